@@ -0,0 +1,3 @@
+package bloggerpb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative bloggerpb/options.proto
@@ -0,0 +1,95 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: bloggerpb/options.proto
+
+package bloggerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var file_bloggerpb_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         50701,
+		Name:          "blogger.sensitive",
+		Tag:           "varint,50701,opt,name=sensitive",
+		Filename:      "bloggerpb/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// Marks a field whose value is masked before the message is logged by the
+	// blogger interceptors, e.g. `string password = 1 [(blogger.sensitive) = true];`
+	//
+	// optional bool sensitive = 50701;
+	E_Sensitive = &file_bloggerpb_options_proto_extTypes[0]
+)
+
+var File_bloggerpb_options_proto protoreflect.FileDescriptor
+
+var file_bloggerpb_options_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x62, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x70, 0x62, 0x2f, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x62, 0x6c, 0x6f, 0x67, 0x67,
+	0x65, 0x72, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x3a, 0x3d, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76,
+	0x65, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x8d, 0x8c, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74,
+	0x69, 0x76, 0x65, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x70, 0x61, 0x77, 0x61, 0x74, 0x74, 0x68, 0x69, 0x72, 0x2f, 0x62, 0x6c, 0x6f, 0x67,
+	0x67, 0x65, 0x72, 0x2f, 0x62, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x70, 0x62, 0x3b, 0x62, 0x6c,
+	0x6f, 0x67, 0x67, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var file_bloggerpb_options_proto_goTypes = []any{
+	(*descriptorpb.FieldOptions)(nil), // 0: google.protobuf.FieldOptions
+}
+var file_bloggerpb_options_proto_depIdxs = []int32{
+	0, // 0: blogger.sensitive:extendee -> google.protobuf.FieldOptions
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_bloggerpb_options_proto_init() }
+func file_bloggerpb_options_proto_init() {
+	if File_bloggerpb_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_bloggerpb_options_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_bloggerpb_options_proto_goTypes,
+		DependencyIndexes: file_bloggerpb_options_proto_depIdxs,
+		ExtensionInfos:    file_bloggerpb_options_proto_extTypes,
+	}.Build()
+	File_bloggerpb_options_proto = out.File
+	file_bloggerpb_options_proto_rawDesc = nil
+	file_bloggerpb_options_proto_goTypes = nil
+	file_bloggerpb_options_proto_depIdxs = nil
+}
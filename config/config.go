@@ -1,60 +1,81 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"strconv"
 
-	"gopkg.in/yaml.v3"
+	"github.com/pawatthir/blogger/logger"
 )
 
-type LogConfig struct {
-	Env         string `yaml:"env" mapstructure:"env"`
-	ServiceName string `yaml:"serviceName" mapstructure:"serviceName"`
-	Level       string `yaml:"level" mapstructure:"level"`
-	UseJSON     bool   `yaml:"useJsonEncoder" mapstructure:"useJsonEncoder"`
-	FileEnabled bool   `yaml:"fileEnabled" mapstructure:"fileEnabled"`
-	FilePath    string `yaml:"filePath" mapstructure:"filePath"`
-	FileSize    int    `yaml:"fileSize" mapstructure:"fileSize"`
-	MaxAge      int    `yaml:"maxAge" mapstructure:"maxAge"`
-	MaxBackups  int    `yaml:"maxBackups" mapstructure:"maxBackups"`
-}
+// LogConfig is the struct logger.Init consumes, so a loaded config is passed to it
+// as is.
+type LogConfig = logger.Config
 
 type Config struct {
-	Log LogConfig `yaml:"log"`
+	Log LogConfig `yaml:"log" json:"log"`
 }
 
-func LoadFromFile(configPath string) (*LogConfig, error) {
-	data, err := os.ReadFile(configPath)
+// LoadFromFile reads a YAML, JSON or TOML file, picked by extension, and applies
+// BLOGGER_* environment overrides. Fields missing from the file stay zero; use
+// Load for defaults.
+func LoadFromFile(configPath string, opts ...LoadOption) (*LogConfig, error) {
+	data, err := readFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	options := newLoadOptions(opts)
+	var config LogConfig
+	_, decodeErr, err := decodeConfigFile(configPath, data, &config, options.strict)
+	if err != nil {
+		return nil, err
 	}
+	applyEnvOverrides(&config)
 
-	applyEnvOverrides(&config.Log)
-	return &config.Log, nil
+	if options.strict {
+		if err := errors.Join(decodeErr, envErrors(), config.Validate()); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
 }
 
-func LoadFromEnv() *LogConfig {
-	// Get defaults first
-	defaultEnv := getEnvOrDefault("BLOGGER_ENV", "local")
-	defaults := GetDefault(defaultEnv)
-	
-	config := &LogConfig{
-		Env:         getEnvOrDefault("BLOGGER_ENV", defaults.Env),
-		ServiceName: getEnvOrDefault("BLOGGER_SERVICE_NAME", defaults.ServiceName),
-		Level:       getEnvOrDefault("BLOGGER_LOG_LEVEL", defaults.Level),
-		UseJSON:     getEnvBoolOrDefault("BLOGGER_USE_JSON", defaults.UseJSON),
-		FileEnabled: getEnvBoolOrDefault("BLOGGER_FILE_ENABLED", defaults.FileEnabled),
-		FilePath:    getEnvOrDefault("BLOGGER_FILE_PATH", defaults.FilePath),
-		FileSize:    getEnvIntOrDefault("BLOGGER_FILE_SIZE", defaults.FileSize),
-		MaxAge:      getEnvIntOrDefault("BLOGGER_MAX_AGE", defaults.MaxAge),
-		MaxBackups:  getEnvIntOrDefault("BLOGGER_MAX_BACKUPS", defaults.MaxBackups),
+// envErrors reports BLOGGER_* variables that are set but cannot be parsed, which
+// the lenient loaders silently ignore. It parses them exactly as
+// applyEnvOverrides does, into a scratch config.
+func envErrors() error {
+	var errs logger.ValidationErrors
+	for _, binding := range envBindings {
+		value := os.Getenv(binding.key)
+		if value == "" {
+			continue
+		}
+		if err := binding.apply(&LogConfig{}, value); err != nil {
+			errs = append(errs, logger.ValidationError{Field: binding.key, Message: err.Error()})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func LoadFromEnvWithOptions(opts ...LoadOption) (*LogConfig, error) {
+	config := LoadFromEnv()
+	if !newLoadOptions(opts).strict {
+		return config, nil
+	}
+	if err := errors.Join(envErrors(), config.Validate()); err != nil {
+		return nil, err
 	}
+	return config, nil
+}
+
+func LoadFromEnv() *LogConfig {
+	config := GetDefault(getEnvOrDefault("BLOGGER_ENV", "local"))
+	applyEnvOverrides(config)
 
 	if config.Env == "local" || config.Env == "development" {
 		config.UseJSON = false
@@ -65,85 +86,65 @@ func LoadFromEnv() *LogConfig {
 }
 
 func GetDefault(env string) *LogConfig {
-	if env == "local" || env == "development" {
-		return &LogConfig{
-			Env:         env,
-			ServiceName: "blogger-service",
-			Level:       "debug",
-			UseJSON:     false,
-			FileEnabled: false,
-			FilePath:    "logs/app.log",
-			FileSize:    100,
-			MaxAge:      30,
-			MaxBackups:  3,
-		}
-	}
+	config := logger.DefaultConfig(env)
+	return &config
+}
 
-	return &LogConfig{
-		Env:         env,
-		ServiceName: "blogger-service",
-		Level:       "info",
-		UseJSON:     true,
-		FileEnabled: false,
-		FilePath:    "logs/app.log",
-		FileSize:    100,
-		MaxAge:      30,
-		MaxBackups:  3,
-	}
+type envBinding struct {
+	key   string
+	field string
+	// apply parses value into config, leaving it unchanged when value is invalid.
+	apply func(config *LogConfig, value string) error
 }
 
-func applyEnvOverrides(config *LogConfig) {
-	if env := os.Getenv("BLOGGER_ENV"); env != "" {
-		config.Env = env
-	}
-	if serviceName := os.Getenv("BLOGGER_SERVICE_NAME"); serviceName != "" {
-		config.ServiceName = serviceName
-	}
-	if level := os.Getenv("BLOGGER_LOG_LEVEL"); level != "" {
-		config.Level = level
-	}
-	if useJSON := os.Getenv("BLOGGER_USE_JSON"); useJSON != "" {
-		config.UseJSON = strings.ToLower(useJSON) == "true"
-	}
-	if fileEnabled := os.Getenv("BLOGGER_FILE_ENABLED"); fileEnabled != "" {
-		config.FileEnabled = strings.ToLower(fileEnabled) == "true"
-	}
-	if filePath := os.Getenv("BLOGGER_FILE_PATH"); filePath != "" {
-		config.FilePath = filePath
+// envBindings lists every BLOGGER_* variable with the config field it overrides.
+var envBindings = []envBinding{
+	{key: "BLOGGER_ENV", field: "env", apply: func(c *LogConfig, v string) error { c.Env = v; return nil }},
+	{key: "BLOGGER_SERVICE_NAME", field: "serviceName", apply: func(c *LogConfig, v string) error { c.ServiceName = v; return nil }},
+	{key: "BLOGGER_LOG_LEVEL", field: "level", apply: func(c *LogConfig, v string) error { c.Level = v; return nil }},
+	{key: "BLOGGER_USE_JSON", field: "useJsonEncoder", apply: func(c *LogConfig, v string) error { return setBool(&c.UseJSON, v) }},
+	{key: "BLOGGER_FILE_ENABLED", field: "fileEnabled", apply: func(c *LogConfig, v string) error { return setBool(&c.FileEnabled, v) }},
+	{key: "BLOGGER_FILE_PATH", field: "filePath", apply: func(c *LogConfig, v string) error { c.FilePath = v; return nil }},
+	{key: "BLOGGER_FILE_SIZE", field: "fileSize", apply: func(c *LogConfig, v string) error { return setPositiveInt(&c.FileSize, v) }},
+	{key: "BLOGGER_MAX_AGE", field: "maxAge", apply: func(c *LogConfig, v string) error { return setPositiveInt(&c.MaxAge, v) }},
+	{key: "BLOGGER_MAX_BACKUPS", field: "maxBackups", apply: func(c *LogConfig, v string) error { return setPositiveInt(&c.MaxBackups, v) }},
+}
+
+// applyEnvOverrides applies the BLOGGER_* variables that are set and valid and
+// returns the variable applied for each field.
+func applyEnvOverrides(config *LogConfig) map[string]string {
+	applied := map[string]string{}
+	for _, binding := range envBindings {
+		if value := os.Getenv(binding.key); value != "" && binding.apply(config, value) == nil {
+			applied[binding.field] = binding.key
+		}
 	}
+	return applied
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// setBool accepts what strconv.ParseBool does: 1, t, true, 0, f, false in any
+// of their usual cases.
+func setBool(target *bool, value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
 	}
-	return defaultValue
+	*target = parsed
+	return nil
 }
 
-func getEnvBoolOrDefault(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		return strings.ToLower(value) == "true"
+func setPositiveInt(target *int, value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("invalid positive integer %q", value)
 	}
-	return defaultValue
+	*target = parsed
+	return nil
 }
 
-func getEnvIntOrDefault(key string, defaultValue int) int {
+func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
-		if intValue := parseIntSafe(value); intValue > 0 {
-			return intValue
-		}
+		return value
 	}
 	return defaultValue
 }
-
-func parseIntSafe(s string) int {
-	var result int
-	for _, c := range s {
-		if c >= '0' && c <= '9' {
-			result = result*10 + int(c-'0')
-		} else {
-			return 0
-		}
-	}
-	return result
-}
\ No newline at end of file
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pawatthir/blogger/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a field in Effective.Sources. File and environment sources carry
+// the path or variable name, e.g. "file:config.yaml" or "env:BLOGGER_LOG_LEVEL".
+const (
+	SourceDefault = "default"
+	SourceOption  = "option"
+)
+
+type loadOptions struct {
+	strict    bool
+	file      string
+	fileData  []byte
+	overrides []func(*LogConfig)
+}
+
+type LoadOption func(*loadOptions)
+
+// WithStrict makes loading fail fast: unknown keys, unparsable BLOGGER_* values
+// and anything LogConfig.Validate rejects are returned as one error instead of
+// being ignored or replaced by defaults.
+func WithStrict() LoadOption {
+	return func(o *loadOptions) {
+		o.strict = true
+	}
+}
+
+// WithFile adds a YAML, JSON or TOML file layer to Load, picked by extension.
+func WithFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.file = path
+		o.fileData = nil
+	}
+}
+
+// withFileData is WithFile for content already read, as the watcher does.
+func withFileData(path string, data []byte) LoadOption {
+	return func(o *loadOptions) {
+		o.file = path
+		o.fileData = data
+	}
+}
+
+// WithOverride changes the config after every other layer. Fields it changes are
+// attributed to SourceOption.
+func WithOverride(override func(*LogConfig)) LoadOption {
+	return func(o *loadOptions) {
+		o.overrides = append(o.overrides, override)
+	}
+}
+
+func WithLevel(level string) LoadOption {
+	return WithOverride(func(c *LogConfig) { c.Level = level })
+}
+
+func WithServiceName(serviceName string) LoadOption {
+	return WithOverride(func(c *LogConfig) { c.ServiceName = serviceName })
+}
+
+func newLoadOptions(opts []LoadOption) loadOptions {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Effective is a loaded config together with the layer each field came from,
+// keyed by YAML path such as "level" or "sampling.tick".
+type Effective struct {
+	Config  LogConfig
+	Sources map[string]string
+}
+
+// Load builds the config logger.Init consumes from layers applied in order:
+// logger.DefaultConfig for the resolved env, the file given by WithFile, the
+// BLOGGER_* environment variables and finally WithOverride options.
+func Load(opts ...LoadOption) (*LogConfig, error) {
+	effective, err := LoadEffective(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &effective.Config, nil
+}
+
+// LoadEffective is Load that also reports the source of every field.
+func LoadEffective(opts ...LoadOption) (*Effective, error) {
+	options := newLoadOptions(opts)
+
+	data := options.fileData
+	if options.file != "" && data == nil {
+		read, err := readFile(options.file)
+		if err != nil {
+			return nil, err
+		}
+		data = read
+	}
+
+	// The defaults depend on env, which any later layer may set, so the layers are
+	// applied once on an empty config to find it.
+	var probe LogConfig
+	if options.file != "" {
+		if _, _, err := decodeConfigFile(options.file, data, &probe, false); err != nil {
+			return nil, err
+		}
+	}
+	applyEnvOverrides(&probe)
+	for _, override := range options.overrides {
+		override(&probe)
+	}
+	env := probe.Env
+	if env == "" {
+		env = "local"
+	}
+
+	config := logger.DefaultConfig(env)
+	sources := map[string]string{}
+	visitFields(reflect.ValueOf(config), "", func(path string, _ reflect.Value) {
+		sources[path] = SourceDefault
+	})
+
+	var decodeErr error
+	if options.file != "" {
+		keys, typeErr, err := decodeConfigFile(options.file, data, &config, options.strict)
+		if err != nil {
+			return nil, err
+		}
+		decodeErr = typeErr
+		visitFields(reflect.ValueOf(config), "", func(path string, _ reflect.Value) {
+			if hasKey(keys, path) {
+				sources[path] = "file:" + options.file
+			}
+		})
+	}
+
+	for field, key := range applyEnvOverrides(&config) {
+		sources[field] = "env:" + key
+	}
+
+	if len(options.overrides) > 0 {
+		// Values are compared in their printed form, which also snapshots maps and
+		// slices an override may mutate in place.
+		before := map[string]string{}
+		visitFields(reflect.ValueOf(config), "", func(path string, value reflect.Value) {
+			before[path] = formatValue(value)
+		})
+		for _, override := range options.overrides {
+			override(&config)
+		}
+		visitFields(reflect.ValueOf(config), "", func(path string, value reflect.Value) {
+			if before[path] != formatValue(value) {
+				sources[path] = SourceOption
+			}
+		})
+	}
+
+	if options.strict {
+		if err := errors.Join(decodeErr, envErrors(), config.Validate()); err != nil {
+			return nil, err
+		}
+	}
+	return &Effective{Config: config, Sources: sources}, nil
+}
+
+// String lists every field with its value and source, one per line. Output
+// header values, which usually carry credentials, are masked.
+func (e *Effective) String() string {
+	var buf bytes.Buffer
+	writer := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	visitFields(reflect.ValueOf(e.Config), "", func(path string, value reflect.Value) {
+		if outputs, ok := value.Interface().([]logger.OutputConfig); ok {
+			value = reflect.ValueOf(maskOutputHeaders(outputs))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t(%s)\n", path, formatValue(value), e.Sources[path])
+	})
+	_ = writer.Flush()
+	return buf.String()
+}
+
+// maskOutputHeaders returns a copy of outputs whose header values are masked.
+func maskOutputHeaders(outputs []logger.OutputConfig) []logger.OutputConfig {
+	if outputs == nil {
+		return nil
+	}
+	masked := make([]logger.OutputConfig, len(outputs))
+	for i, output := range outputs {
+		if output.Headers != nil {
+			headers := make(map[string]string, len(output.Headers))
+			for key := range output.Headers {
+				headers[key] = "*****"
+			}
+			output.Headers = headers
+		}
+		masked[i] = output
+	}
+	return masked
+}
+
+func formatValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if value.IsNil() {
+			return "-"
+		}
+		raw, err := json.Marshal(value.Interface())
+		if err != nil {
+			return fmt.Sprint(value.Interface())
+		}
+		return string(raw)
+	case reflect.String:
+		return fmt.Sprintf("%q", value.String())
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
+
+// visitFields calls visit for every leaf of a config struct. Nested structs are
+// followed; pointers, maps and slices are leaves.
+func visitFields(value reflect.Value, prefix string, visit func(path string, value reflect.Value)) {
+	typeOf := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := typeOf.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		path := prefix + name
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Struct {
+			visitFields(fieldValue, path+".", visit)
+			continue
+		}
+		visit(path, fieldValue)
+	}
+}
+
+func hasKey(keys map[string]interface{}, path string) bool {
+	current := keys
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return data, nil
+}
+
+// decodeConfigFile decodes the log section of data into config, leaving fields
+// the file does not mention untouched. JSON and TOML are converted to YAML first
+// so every format goes through the same yaml tags. It returns the keys present
+// in the log section and, in strict mode, the unknown-key errors separately so
+// they can be reported with the validation errors.
+func decodeConfigFile(path string, data []byte, config *LogConfig, strict bool) (map[string]interface{}, error, error) {
+	normalized, err := normalizeToYAML(path, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	wrapper := Config{Log: *config}
+	decoder := yaml.NewDecoder(bytes.NewReader(normalized))
+	decoder.KnownFields(strict)
+	decodeErr := decoder.Decode(&wrapper)
+	if errors.Is(decodeErr, io.EOF) {
+		decodeErr = nil
+	}
+	var typeErr *yaml.TypeError
+	if decodeErr != nil && !(strict && errors.As(decodeErr, &typeErr)) {
+		return nil, nil, fmt.Errorf("failed to unmarshal config: %w", decodeErr)
+	}
+	*config = wrapper.Log
+
+	var raw map[string]interface{}
+	_ = yaml.Unmarshal(normalized, &raw)
+	keys, _ := raw["log"].(map[string]interface{})
+	return keys, decodeErr, nil
+}
+
+func normalizeToYAML(path string, data []byte) ([]byte, error) {
+	var generic map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &generic); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	return yaml.Marshal(generic)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+type WatchOptions struct {
+	// Interval between checks of the file for changes. Defaults to 2s.
+	Interval time.Duration
+	// OnReject receives changes that could not be applied live, or the error when
+	// the file could not be loaded or failed validation.
+	OnReject func(rejected []logger.RejectedChange, err error)
+	// OnReload is called after a new config has been applied.
+	OnReload func(config *LogConfig)
+	// LoadOptions are used for every reload, e.g. WithStrict to reject unknown keys.
+	LoadOptions []LoadOption
+}
+
+// Watcher re-reads a config file when it changes or the process receives
+// SIGHUP, and applies it to the running logger through logger.Reconfigure.
+type Watcher struct {
+	path    string
+	options WatchOptions
+
+	mu       sync.Mutex
+	checksum [sha256.Size]byte
+	modTime  time.Time
+	size     int64
+
+	hangup  chan os.Signal
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// WatchFile starts watching configPath. The file's current content is taken as
+// already applied, so only later edits trigger a reload.
+func WatchFile(configPath string, options WatchOptions) (*Watcher, error) {
+	if options.Interval <= 0 {
+		options.Interval = defaultWatchInterval
+	}
+
+	w := &Watcher{
+		path:    configPath,
+		options: options,
+		hangup:  make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	data, info, err := w.read()
+	if err != nil {
+		return nil, err
+	}
+	w.remember(data, info)
+
+	signal.Notify(w.hangup, syscall.SIGHUP)
+	go w.run()
+	return w, nil
+}
+
+// Stop ends the watch and waits for an in-flight reload to finish.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.stopped
+	})
+}
+
+// Reload re-reads and applies the config immediately, even if the file did not
+// change.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, info, err := w.read()
+	if err != nil {
+		w.reject(nil, err)
+		return err
+	}
+	return w.apply(data, info)
+}
+
+func (w *Watcher) run() {
+	defer close(w.stopped)
+	defer signal.Stop(w.hangup)
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.hangup:
+			_ = w.Reload()
+		case <-ticker.C:
+			w.reloadIfChanged()
+		}
+	}
+}
+
+func (w *Watcher) reloadIfChanged() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil || (info.ModTime().Equal(w.modTime) && info.Size() == w.size) {
+		return
+	}
+
+	data, info, err := w.read()
+	if err != nil {
+		w.reject(nil, err)
+		return
+	}
+	if sha256.Sum256(data) == w.checksum {
+		w.remember(data, info)
+		return
+	}
+	_ = w.apply(data, info)
+}
+
+func (w *Watcher) read() ([]byte, os.FileInfo, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat config file: %w", err)
+	}
+	return data, info, nil
+}
+
+func (w *Watcher) remember(data []byte, info os.FileInfo) {
+	w.checksum = sha256.Sum256(data)
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+}
+
+func (w *Watcher) apply(data []byte, info os.FileInfo) error {
+	// The file is remembered even when rejected so a broken edit is reported once.
+	w.remember(data, info)
+
+	effective, err := LoadEffective(append([]LoadOption{withFileData(w.path, data)}, w.options.LoadOptions...)...)
+	if err != nil {
+		w.reject(nil, err)
+		return err
+	}
+	config := &effective.Config
+
+	rejected, err := logger.Reconfigure(*config)
+	if err != nil {
+		err = fmt.Errorf("invalid config: %w", err)
+		w.reject(nil, err)
+		return err
+	}
+	if len(rejected) > 0 {
+		w.reject(rejected, nil)
+	}
+
+	slog.InfoContext(context.Background(), "logger config reloaded", slog.String("path", w.path))
+	if w.options.OnReload != nil {
+		w.options.OnReload(config)
+	}
+	return nil
+}
+
+func (w *Watcher) reject(rejected []logger.RejectedChange, err error) {
+	ctx := context.Background()
+	if err != nil {
+		slog.ErrorContext(ctx, "logger config reload failed", slog.String("path", w.path), slog.String("error", err.Error()))
+	}
+	for _, change := range rejected {
+		slog.WarnContext(ctx, "logger config change rejected",
+			slog.String("path", w.path),
+			slog.String("field", change.Field),
+			slog.String("reason", change.Reason),
+		)
+	}
+	if w.options.OnReject != nil {
+		w.options.OnReject(rejected, err)
+	}
+}
@@ -0,0 +1,321 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy decides what an async output does with an entry when its buffer
+// is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for room, so nothing is lost but logging can stall.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest discards the entry being logged.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowDropOldest discards the oldest queued entry to make room.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropBelowLevel discards entries below AsyncConfig.DropLevel and
+	// blocks for the rest.
+	OverflowDropBelowLevel OverflowPolicy = "drop-below-level"
+)
+
+const (
+	defaultAsyncBufferSize    = 4096
+	defaultAsyncFlushInterval = time.Second
+	defaultAsyncDropLevel     = "warn"
+)
+
+// AsyncConfig moves encoded entries off the calling goroutine: each output gets a
+// bounded queue drained by a background writer.
+type AsyncConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	// BufferSize is the number of entries queued per output, 4096 when zero.
+	BufferSize int            `yaml:"bufferSize" json:"bufferSize" mapstructure:"bufferSize"`
+	Overflow   OverflowPolicy `yaml:"overflow" json:"overflow" mapstructure:"overflow"`
+	// DropLevel is the level entries must reach to survive a full buffer under
+	// OverflowDropBelowLevel, warn when empty.
+	DropLevel string `yaml:"dropLevel" json:"dropLevel" mapstructure:"dropLevel"`
+	// FlushInterval is how often the outputs are synced, one second when zero.
+	FlushInterval time.Duration `yaml:"flushInterval" json:"flushInterval" mapstructure:"flushInterval"`
+}
+
+func validOverflowPolicy(policy OverflowPolicy) bool {
+	switch policy {
+	case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel:
+		return true
+	default:
+		return false
+	}
+}
+
+// AsyncStats describes the async outputs of a Logger, summed over outputs.
+type AsyncStats struct {
+	QueueDepth int
+	Capacity   int
+	Written    uint64
+	Dropped    uint64
+	// Blocked counts the entries that had to wait for room.
+	Blocked uint64
+}
+
+// GetAsyncStats returns the stats of the default Logger.
+func GetAsyncStats() AsyncStats {
+	return Default().AsyncStats()
+}
+
+func (l *Logger) AsyncStats() AsyncStats {
+	var stats AsyncStats
+	for _, queue := range l.asyncQueues {
+		queue.mu.Lock()
+		stats.QueueDepth += queue.size
+		queue.mu.Unlock()
+		stats.Capacity += len(queue.items)
+		stats.Written += queue.written.Load()
+		stats.Dropped += queue.dropped.Load()
+		stats.Blocked += queue.blocked.Load()
+	}
+	return stats
+}
+
+type asyncRecord struct {
+	entry zapcore.Entry
+	data  *buffer.Buffer
+}
+
+// asyncQueue is a ring buffer of encoded entries written to out by one goroutine.
+type asyncQueue struct {
+	out       Sink
+	policy    OverflowPolicy
+	dropLevel zapcore.Level
+	interval  time.Duration
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	idle    *sync.Cond
+	items   []asyncRecord
+	head    int
+	size    int
+	writing bool
+	closed  bool
+
+	wake   chan struct{}
+	stop   chan struct{}
+	exited chan struct{}
+
+	written atomic.Uint64
+	dropped atomic.Uint64
+	blocked atomic.Uint64
+}
+
+func newAsyncQueue(out Sink, config AsyncConfig) *asyncQueue {
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+	interval := config.FlushInterval
+	if interval <= 0 {
+		interval = defaultAsyncFlushInterval
+	}
+	dropLevel := config.DropLevel
+	if dropLevel == "" {
+		dropLevel = defaultAsyncDropLevel
+	}
+	policy := config.Overflow
+	if policy == "" {
+		policy = OverflowBlock
+	}
+
+	q := &asyncQueue{
+		out:       out,
+		policy:    policy,
+		dropLevel: getZapLogLevel(dropLevel),
+		interval:  interval,
+		items:     make([]asyncRecord, size),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+	q.notFull = sync.NewCond(&q.mu)
+	q.idle = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *asyncQueue) push(record asyncRecord) {
+	q.mu.Lock()
+	for !q.closed && q.size == len(q.items) {
+		switch {
+		case q.policy == OverflowDropNewest,
+			q.policy == OverflowDropBelowLevel && record.entry.Level < q.dropLevel:
+			q.mu.Unlock()
+			q.dropped.Add(1)
+			record.data.Free()
+			return
+		case q.policy == OverflowDropOldest:
+			q.items[q.head].data.Free()
+			q.items[q.head] = asyncRecord{}
+			q.head = (q.head + 1) % len(q.items)
+			q.size--
+			q.dropped.Add(1)
+		default:
+			q.blocked.Add(1)
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		// The writer is gone; write in place so nothing is lost.
+		q.mu.Unlock()
+		q.writeBatch([]asyncRecord{record})
+		return
+	}
+	q.items[(q.head+q.size)%len(q.items)] = record
+	q.size++
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.exited)
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stop:
+			q.drain()
+			return
+		case <-q.wake:
+			q.drain()
+		case <-ticker.C:
+			q.drain()
+			_ = q.out.Sync()
+		}
+	}
+}
+
+// drain writes everything queued, one batch at a time.
+func (q *asyncQueue) drain() {
+	for {
+		q.mu.Lock()
+		if q.size == 0 {
+			q.mu.Unlock()
+			return
+		}
+		batch := make([]asyncRecord, q.size)
+		for i := range batch {
+			index := (q.head + i) % len(q.items)
+			batch[i] = q.items[index]
+			q.items[index] = asyncRecord{}
+		}
+		q.head, q.size = 0, 0
+		q.writing = true
+		q.notFull.Broadcast()
+		q.mu.Unlock()
+
+		q.writeBatch(batch)
+
+		q.mu.Lock()
+		q.writing = false
+		q.idle.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+func (q *asyncQueue) writeBatch(batch []asyncRecord) {
+	for _, record := range batch {
+		_ = q.out.Write(record.entry, record.data.Bytes())
+		record.data.Free()
+	}
+	q.written.Add(uint64(len(batch)))
+}
+
+// flush waits until everything queued so far is written, then syncs out.
+func (q *asyncQueue) flush() error {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	q.mu.Lock()
+	for !q.closed && (q.size > 0 || q.writing) {
+		q.idle.Wait()
+	}
+	q.mu.Unlock()
+	return q.out.Sync()
+}
+
+// close writes what is left and stops the writer. Entries logged afterwards are
+// written synchronously.
+func (q *asyncQueue) close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.notFull.Broadcast()
+	q.idle.Broadcast()
+	q.mu.Unlock()
+
+	close(q.stop)
+	<-q.exited
+	return errors.Join(syncErrors(q.out.Sync())...)
+}
+
+// asyncCore encodes on the calling goroutine, like zapcore.NewCore, and hands the
+// bytes to an asyncQueue.
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc   zapcore.Encoder
+	queue *asyncQueue
+}
+
+func newAsyncCore(enc zapcore.Encoder, queue *asyncQueue, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &asyncCore{LevelEnabler: enabler, enc: enc, queue: queue}
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(clone)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: clone, queue: c.queue}
+}
+
+func (c *asyncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(entry.Level) {
+		return nil
+	}
+	data, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	c.queue.push(asyncRecord{entry: entry, data: data})
+	if entry.Level > zapcore.ErrorLevel {
+		// Panic and fatal entries may be the last ones; write them before returning.
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.queue.flush()
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+const truncatedMarker = "...[truncated]"
+
+// DefaultSkipContentTypes is used when BodyLogConfig.SkipContentTypes is nil.
+// Entries ending in "/" match every subtype.
+var DefaultSkipContentTypes = []string{
+	"multipart/",
+	"image/",
+	"video/",
+	"audio/",
+	"font/",
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+}
+
+type BodyLogConfig struct {
+	// MaxRequestBytes and MaxResponseBytes cap the logged body size; zero means no limit.
+	MaxRequestBytes  int      `yaml:"maxRequestBytes" json:"maxRequestBytes" mapstructure:"maxRequestBytes"`
+	MaxResponseBytes int      `yaml:"maxResponseBytes" json:"maxResponseBytes" mapstructure:"maxResponseBytes"`
+	SkipContentTypes []string `yaml:"skipContentTypes" json:"skipContentTypes" mapstructure:"skipContentTypes"`
+}
+
+func SetBodyLogConfig(config BodyLogConfig) {
+	Default().SetBodyLogConfig(config)
+}
+
+func GetBodyLogConfig() BodyLogConfig {
+	return Default().BodyLogConfig()
+}
+
+func (l *Logger) SetBodyLogConfig(config BodyLogConfig) {
+	l.body.Store(&config)
+}
+
+func (l *Logger) BodyLogConfig() BodyLogConfig {
+	return *l.body.Load()
+}
+
+func (c BodyLogConfig) skipContentTypes() []string {
+	if c.SkipContentTypes == nil {
+		return DefaultSkipContentTypes
+	}
+	return c.SkipContentTypes
+}
+
+// ShouldSkip reports whether bodies of contentType are left out of canonical
+// logs, so middleware need not capture them.
+func (c BodyLogConfig) ShouldSkip(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	for _, skip := range c.skipContentTypes() {
+		skip = strings.ToLower(skip)
+		if strings.HasSuffix(skip, "/") && strings.HasPrefix(mediaType, skip) {
+			return true
+		}
+		if mediaType == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalBodyFields builds the log fields for a request or response body. Bodies
+// are redacted before truncation so a cut never exposes a value the redactor would
+// have hidden. Truncated or skipped bodies carry <key>_size with the original length,
+// which is size when the middleware captured only the first bytes of the body.
+func canonicalBodyFields(key string, logKey string, body []byte, size int, contentType string, maxBytes int, config BodyLogConfig, redactor *Redactor) []any {
+	if size < len(body) {
+		size = len(body)
+	}
+	partial := size > len(body)
+	if partial {
+		body = trimPartialRune(body)
+	}
+
+	if config.ShouldSkip(contentType) || (len(body) > 0 && !utf8.Valid(body)) {
+		if contentType == "" {
+			contentType = "binary"
+		}
+		return []any{
+			slog.String(key, fmt.Sprintf("[skipped %s body]", contentType)),
+			slog.Int(key+"_size", size),
+		}
+	}
+
+	_, pathMatched := redactor.MatchPath(logKey)
+	if partial && !pathMatched && looksLikeJSON(body) {
+		// Field rules need the whole document; a cut one would be logged as it is.
+		return []any{
+			slog.String(key, "[truncated json body]"),
+			slog.Int(key+"_size", size),
+			slog.Bool(key+"_truncated", true),
+		}
+	}
+
+	redacted := redactor.RedactBody(logKey, body)
+	if !partial && (maxBytes <= 0 || len(body) <= maxBytes) {
+		return []any{slog.Any(key, redacted)}
+	}
+
+	var serialized string
+	switch v := redacted.(type) {
+	case string:
+		serialized = v
+	default:
+		raw, _ := json.Marshal(v)
+		serialized = string(raw)
+	}
+	if !partial && len(serialized) <= maxBytes {
+		return []any{slog.Any(key, redacted)}
+	}
+	if pathMatched {
+		return []any{
+			slog.Any(key, redacted),
+			slog.Int(key+"_size", size),
+			slog.Bool(key+"_truncated", true),
+		}
+	}
+	if maxBytes > 0 {
+		serialized = truncateUTF8(serialized, maxBytes)
+	}
+
+	return []any{
+		slog.String(key, serialized+truncatedMarker),
+		slog.Int(key+"_size", size),
+		slog.Bool(key+"_truncated", true),
+	}
+}
+
+// trimPartialRune drops a rune cut in half at the end of a captured prefix.
+func trimPartialRune(body []byte) []byte {
+	for i := 0; i < utf8.UTFMax-1 && len(body) > 0 && !utf8.Valid(body); i++ {
+		body = body[:len(body)-1]
+	}
+	return body
+}
+
+func looksLikeJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -2,7 +2,6 @@ package logger
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"html/template"
 	"log/slog"
@@ -30,6 +29,13 @@ type CanonicalLog struct {
 	Duration  time.Duration
 	Message   string
 	Level     slog.Level
+	// Content types drive BodyLogConfig.SkipContentTypes; empty means unknown.
+	RequestContentType  string
+	ResponseContentType string
+	// Sizes of the bodies when the middleware passed only their first bytes, or
+	// none for a skipped content type; zero means the whole body was passed.
+	RequestSize  int
+	ResponseSize int
 }
 
 type ExceptionError struct {
@@ -53,13 +59,18 @@ func (e *ExceptionError) Error() string {
 	return e.DebugMessage
 }
 
+const defaultCanonicalLogTemplate = "[{{.Transport}}][{{.Traffic}}] {{.Method}} {{.Status}} {{.Path}} {{.Duration}} - {{.Message}}"
+
 func CompileCanonicalLogTemplate() {
-	logTemplate := "[{{.Transport}}][{{.Traffic}}] {{.Method}} {{.Status}} {{.Path}} {{.Duration}} - {{.Message}}"
-	compiled, err := template.New("log_template").Parse(logTemplate)
+	canonicalLogTemplate = parseCanonicalLogTemplate(defaultCanonicalLogTemplate)
+}
+
+func parseCanonicalLogTemplate(text string) *template.Template {
+	compiled, err := template.New("log_template").Parse(text)
 	if err != nil {
 		panic(err)
 	}
-	canonicalLogTemplate = compiled
+	return compiled
 }
 
 func GetCanonicalLogTemplate() (*template.Template, error) {
@@ -69,22 +80,35 @@ func GetCanonicalLogTemplate() (*template.Template, error) {
 	return nil, errors.New("canonicalLogTemplate is nil")
 }
 
+// CanonicalLogger writes one entry per request through slogger, using the
+// sampling rules, redactor and body limits of the default Logger.
 func CanonicalLogger(ctx context.Context, slogger slog.Logger, level Level, request []byte, response []byte, err error, canonicalLog CanonicalLog, metadata []any) {
-	logKey := canonicalLog.Path
-	var reqFields []any
+	Default().canonicalLogger(ctx, slogger, GetCanonicalLogTemplate, level, request, response, err, canonicalLog, metadata)
+}
 
-	var jsonObj map[string]interface{}
-	if unmarshalErr := json.Unmarshal(request, &jsonObj); unmarshalErr != nil {
-		reqFields = append(reqFields, slog.String("request", string(request)))
-	} else {
-		reqFields = append(reqFields, slog.Any("request", jsonObj))
-	}
+// CanonicalLogger is the package-level CanonicalLogger with the template,
+// redactor, body limits and sampling rules of l. slogger is usually l.Slog(),
+// possibly with extra attributes.
+func (l *Logger) CanonicalLogger(ctx context.Context, slogger slog.Logger, level Level, request []byte, response []byte, err error, canonicalLog CanonicalLog, metadata []any) {
+	l.canonicalLogger(ctx, slogger, l.canonicalTemplate, level, request, response, err, canonicalLog, metadata)
+}
+
+func (l *Logger) canonicalTemplate() (*template.Template, error) {
+	return l.template, nil
+}
 
-	shouldSanitize := Sanitize(logKey)
-	if shouldSanitize {
-		reqFields = []any{slog.String("request", "REDACTED")}
+func (l *Logger) canonicalLogger(ctx context.Context, slogger slog.Logger, logTemplate func() (*template.Template, error), level Level, request []byte, response []byte, err error, canonicalLog CanonicalLog, metadata []any) {
+	logKey := canonicalLog.Path
+	if !l.sampleCanonical(logKey, canonicalLog.Status, err != nil || level == Error) {
+		return
 	}
 
+	redactor := l.Redactor()
+	bodyConfig := l.BodyLogConfig()
+
+	var reqFields []any
+	reqFields = append(reqFields, canonicalBodyFields("request", logKey, request, canonicalLog.RequestSize, canonicalLog.RequestContentType, bodyConfig.MaxRequestBytes, bodyConfig, redactor)...)
+
 	var respFields []any
 	if err != nil {
 		level = Error
@@ -113,25 +137,11 @@ func CanonicalLogger(ctx context.Context, slogger slog.Logger, level Level, requ
 				)))
 			canonicalLog.Message = cErr.DebugMessage
 		} else {
-			var jsonObj map[string]interface{}
-			if err := json.Unmarshal(response, &jsonObj); err != nil {
-				respFields = append(respFields, slog.String("response", string(response)))
-			} else {
-				respFields = append(respFields, slog.Any("response", jsonObj))
-			}
+			respFields = append(respFields, canonicalBodyFields("response", logKey, response, canonicalLog.ResponseSize, canonicalLog.ResponseContentType, bodyConfig.MaxResponseBytes, bodyConfig, redactor)...)
 		}
 	} else {
 		level = Info
-		var jsonObj map[string]interface{}
-		if err := json.Unmarshal(response, &jsonObj); err != nil {
-			respFields = append(respFields, slog.String("response", string(response)))
-		} else {
-			respFields = append(respFields, slog.Any("response", jsonObj))
-		}
-	}
-
-	if shouldSanitize {
-		respFields = []any{slog.String("response", "REDACTED")}
+		respFields = append(respFields, canonicalBodyFields("response", logKey, response, canonicalLog.ResponseSize, canonicalLog.ResponseContentType, bodyConfig.MaxResponseBytes, bodyConfig, redactor)...)
 	}
 
 	var mdFields []any
@@ -142,7 +152,7 @@ func CanonicalLogger(ctx context.Context, slogger slog.Logger, level Level, requ
 
 	var logMsgBuilder strings.Builder
 	var logMsg string
-	logTmpl, logTmplErr := GetCanonicalLogTemplate()
+	logTmpl, logTmplErr := logTemplate()
 	if logTmplErr != nil {
 		logMsg = "failed to get canonical log template"
 	} else {
@@ -171,6 +181,10 @@ func CanonicalLogger(ctx context.Context, slogger slog.Logger, level Level, requ
 	}
 }
 
+// DenyPatterns seeds the PathRules of DefaultRedactionConfig: bodies on paths
+// containing one of them are logged as REDACTED. Changes must be made before
+// Init or New, or be followed by SetRedactor(nil), since the default Redactor
+// is built from them then. Sanitize checks a path against them directly.
 var DenyPatterns = []string{
 	"login",
 	"refresh-token",
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Keys of the attributes middleware attaches to a request context.
+const (
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+	TenantKey    = "tenant"
+	RouteKey     = "route"
+)
+
+type loggerContextKey struct{}
+
+type attrsContextKey struct{}
+
+// WithContext returns a copy of ctx carrying l, for FromContext.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger stored by WithContext, or slog's default
+// logger. Attributes added with ContextWithAttrs are logged either way, as long
+// as the context is passed to the *Context logging methods.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// ContextWithAttrs returns a copy of ctx whose attributes, added by the Handler
+// to every record logged with it, include attrs. An attribute replaces one with
+// the same key already on ctx.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	existing := ContextAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	for _, attr := range existing {
+		if !hasAttrKey(attrs, attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	for i, attr := range attrs {
+		if !hasAttrKey(attrs[i+1:], attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	return context.WithValue(ctx, attrsContextKey{}, merged)
+}
+
+// ContextAttrs returns the attributes attached to ctx. The slice must not be
+// modified.
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextAttr returns the value of the attribute key attached to ctx.
+func ContextAttr(ctx context.Context, key string) (slog.Value, bool) {
+	for _, attr := range ContextAttrs(ctx) {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return ContextWithAttrs(ctx, slog.String(RequestIDKey, requestID))
+}
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return ContextWithAttrs(ctx, slog.String(UserIDKey, userID))
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return ContextWithAttrs(ctx, slog.String(TenantKey, tenant))
+}
+
+// WithRoute attaches the route pattern, such as /orders/:id, rather than the
+// request path.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return ContextWithAttrs(ctx, slog.String(RouteKey, route))
+}
+
+// RequestContext attaches the request and user IDs to ctx, leaving out empty
+// ones, together with the slog logger of l, or of the default Logger for a nil
+// l, so FromContext returns it for the rest of the request.
+func RequestContext(ctx context.Context, l *Logger, requestID, userID string) context.Context {
+	var attrs []slog.Attr
+	if requestID != "" {
+		attrs = append(attrs, slog.String(RequestIDKey, requestID))
+	}
+	if userID != "" {
+		attrs = append(attrs, slog.String(UserIDKey, userID))
+	}
+	if l == nil {
+		l = Default()
+	}
+	return WithContext(ContextWithAttrs(ctx, attrs...), l.Slog())
+}
+
+// RequestIDFromContext returns the request ID attached by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	if value, ok := ContextAttr(ctx, RequestIDKey); ok {
+		return value.String()
+	}
+	return ""
+}
+
+func hasAttrKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// addContextAttrs adds the attributes of ctx that the record does not set
+// itself.
+func addContextAttrs(ctx context.Context, record *slog.Record) {
+	attrs := ContextAttrs(ctx)
+	if len(attrs) == 0 {
+		return
+	}
+	keys := make(map[string]bool, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		keys[attr.Key] = true
+		return true
+	})
+	for _, attr := range attrs {
+		if !keys[attr.Key] {
+			record.AddAttrs(attr)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Logger is a self-contained logger: its own cores, level registry, sampling
+// state, redactor, body limits, canonical template and Datadog fields. Init
+// builds one and installs it as the default that the package-level functions
+// use; New builds independent ones, for example one per tenant or per test.
+type Logger struct {
+	zap  *zap.Logger
+	slog *slog.Logger
+
+	env         string
+	serviceName string
+	version     string
+
+	levels   *levelRegistry
+	template *template.Template
+	redactor atomic.Pointer[Redactor]
+	body     atomic.Pointer[BodyLogConfig]
+
+	sampling         atomic.Pointer[samplingState]
+	pathSampling     atomic.Pointer[[]PathSamplingRule]
+	samplingCounters *samplingCounterTable
+	drops            samplingDropCounters
+	reporter         samplingReporter
+
+	// mu serializes Reconfigure; config is the configuration last applied.
+	mu         sync.Mutex
+	config     Config
+	fileWriter *rotatingFile
+
+	asyncQueues []*asyncQueue
+
+	// tracing is nil unless Config.Tracing is enabled.
+	tracing *tracingState
+
+	// closers release outputs on Shutdown, after the cores are synced. closed
+	// makes the cores drop entries once Shutdown has started.
+	closers      []func() error
+	closed       atomic.Bool
+	releaseOnce  sync.Once
+	releaseErr   error
+	shutdownOnce sync.Once
+	shutdownDone chan struct{}
+	shutdownErr  error
+}
+
+type options struct {
+	config   Config
+	writer   io.Writer
+	redactor *Redactor
+	template string
+	version  string
+
+	tracerProvider trace.TracerProvider
+}
+
+type Option func(*options)
+
+// WithConfig replaces the whole configuration. Options after it change single
+// fields of it.
+func WithConfig(config Config) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+func WithLevel(level string) Option {
+	return func(o *options) {
+		o.config.Level = level
+	}
+}
+
+func WithEnv(env string) Option {
+	return func(o *options) {
+		o.config.Env = env
+	}
+}
+
+func WithServiceName(serviceName string) Option {
+	return func(o *options) {
+		o.config.ServiceName = serviceName
+	}
+}
+
+// WithVersion sets dd.version, which otherwise comes from DD_VERSION.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithWriter sends the console or JSON output to w instead of stdout. The file
+// output, when enabled, is unaffected.
+func WithWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.writer = w
+	}
+}
+
+// WithRedactor replaces the redactor built from Config.Redaction.
+func WithRedactor(r *Redactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// WithCanonicalTemplate replaces the message template of canonical logs. The
+// template receives a CanonicalLog; New panics if it does not parse, like
+// CompileCanonicalLogTemplate.
+func WithCanonicalTemplate(text string) Option {
+	return func(o *options) {
+		o.template = text
+	}
+}
+
+// New builds a Logger without touching the package-level state, so several can
+// run side by side in one process.
+func New(opts ...Option) *Logger {
+	o := options{template: defaultCanonicalLogTemplate}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	config := o.config
+
+	l := &Logger{
+		env:         getEnvOrDefault("DD_ENV", config.Env),
+		serviceName: getEnvOrDefault("DD_SERVICE", config.ServiceName),
+		version:     getEnvOrDefault("DD_VERSION", "unknown"),
+		levels:      newLevelRegistry(),
+		template:    parseCanonicalLogTemplate(o.template),
+		config:      config,
+		tracing:     newTracingState(config.Tracing, o.tracerProvider),
+	}
+	if o.version != "" {
+		l.version = o.version
+	}
+
+	l.levels.reset(getZapLogLevel(config.Level))
+	componentRules, componentErr := compileComponentLevels(config.ComponentLevels)
+	l.levels.setRules(componentRules)
+	l.setSampling(config.Sampling)
+	l.SetBodyLogConfig(config.Body)
+
+	l.zap, l.slog = l.newZapLogger(config, o.writer)
+
+	redactor := o.redactor
+	if redactor == nil {
+		redactor = NewDefaultRedactor()
+		if config.Redaction != nil {
+			configured, err := NewRedactor(*config.Redaction)
+			if err != nil {
+				l.slog.ErrorContext(context.Background(), "invalid redaction config, using defaults", "error", err)
+			} else {
+				redactor = configured
+			}
+		}
+	}
+	l.SetRedactor(redactor)
+	if componentErr != nil {
+		l.slog.ErrorContext(context.Background(), "invalid component levels, ignoring them", "error", componentErr)
+	}
+
+	return l
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	defaultLogger.Store(New())
+}
+
+// Default returns the Logger behind the package-level functions, the one built
+// by the last Init.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+func (l *Logger) Zap() *zap.Logger {
+	return l.zap
+}
+
+func (l *Logger) Slog() *slog.Logger {
+	return l.slog
+}
+
+// Config returns the configuration last applied by New or Reconfigure.
+func (l *Logger) Config() Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.config
+}
+
+// stop ends the sampling reporter of a Logger being shut down or replaced.
+func (l *Logger) stop() {
+	l.startSamplingReporter(nil, SamplingConfig{})
+}
@@ -0,0 +1,75 @@
+// Package kafkatest provides an in-memory logger.KafkaProducer for testing code
+// that logs through a kafka output.
+package kafkatest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pawatthir/blogger/logger"
+)
+
+// Producer is an in-memory logger.KafkaProducer that records every call. The
+// zero value delivers every message.
+type Producer struct {
+	// Fail, when set, decides per message whether delivery fails. call counts
+	// the Produce calls from 1, so a test can fail the first attempt and let
+	// the retry through.
+	Fail func(call int, message logger.KafkaMessage) error
+
+	mu        sync.Mutex
+	calls     [][]logger.KafkaMessage
+	delivered []logger.KafkaMessage
+	closed    bool
+}
+
+func (p *Producer) Produce(_ context.Context, messages []logger.KafkaMessage) []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, append([]logger.KafkaMessage(nil), messages...))
+
+	var results []error
+	for i, message := range messages {
+		var err error
+		if p.Fail != nil {
+			err = p.Fail(len(p.calls), message)
+		}
+		if err != nil {
+			if results == nil {
+				results = make([]error, len(messages))
+			}
+			results[i] = err
+			continue
+		}
+		p.delivered = append(p.delivered, message)
+	}
+	return results
+}
+
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// Calls returns the messages of each Produce call, in order.
+func (p *Producer) Calls() [][]logger.KafkaMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][]logger.KafkaMessage(nil), p.calls...)
+}
+
+// Delivered returns the messages delivered so far, in order.
+func (p *Producer) Delivered() []logger.KafkaMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]logger.KafkaMessage(nil), p.delivered...)
+}
+
+// Closed reports whether Close was called.
+func (p *Producer) Closed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
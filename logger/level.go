@@ -0,0 +1,449 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelRegistry holds the level shared by every core of a Logger plus the per
+// logger_name overrides and rules, so verbosity can change without rebuilding
+// the logger.
+type levelRegistry struct {
+	mu     sync.Mutex
+	global zap.AtomicLevel
+	// named and rules are replaced on every change so the hot path reads them
+	// without locking.
+	named  atomic.Pointer[map[string]zapcore.Level]
+	rules  atomic.Pointer[[]componentLevelRule]
+	timers map[string]*levelTimer
+}
+
+// componentLevelRule matches logger_name exactly, or as a glob when it contains
+// "*". A trailing ".*" also matches the parent, so "svc.payment.*" covers
+// "svc.payment" and "svc.payment.refund".
+type componentLevelRule struct {
+	pattern string
+	level   zapcore.Level
+}
+
+func (r componentLevelRule) matches(name string) bool {
+	if !strings.Contains(r.pattern, "*") {
+		return r.pattern == name
+	}
+	if prefix, ok := strings.CutSuffix(r.pattern, ".*"); ok && !strings.Contains(prefix, "*") {
+		return name == prefix || strings.HasPrefix(name, prefix+".")
+	}
+	matched, _ := path.Match(r.pattern, name)
+	return matched
+}
+
+// compileComponentLevels orders rules so exact names win over globs and longer
+// patterns win over shorter ones.
+func compileComponentLevels(componentLevels map[string]string) ([]componentLevelRule, error) {
+	rules := make([]componentLevelRule, 0, len(componentLevels))
+	for pattern, level := range componentLevels {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("component level %q: %w", pattern, err)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("component level %q: %w", pattern, err)
+		}
+		rules = append(rules, componentLevelRule{pattern: pattern, level: parsed})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		iGlob, jGlob := strings.Contains(rules[i].pattern, "*"), strings.Contains(rules[j].pattern, "*")
+		if iGlob != jGlob {
+			return !iGlob
+		}
+		if len(rules[i].pattern) != len(rules[j].pattern) {
+			return len(rules[i].pattern) > len(rules[j].pattern)
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules, nil
+}
+
+// levelTimer reverts a temporary level; previous is the level before the first
+// bump so stacked bumps still restore the original.
+type levelTimer struct {
+	timer       *time.Timer
+	previous    zapcore.Level
+	hadPrevious bool
+}
+
+func newLevelRegistry() *levelRegistry {
+	registry := &levelRegistry{
+		global: zap.NewAtomicLevelAt(zap.InfoLevel),
+		timers: map[string]*levelTimer{},
+	}
+	registry.named.Store(&map[string]zapcore.Level{})
+	registry.rules.Store(&[]componentLevelRule{})
+	return registry
+}
+
+func (r *levelRegistry) reset(level zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, pending := range r.timers {
+		pending.timer.Stop()
+		delete(r.timers, name)
+	}
+	r.named.Store(&map[string]zapcore.Level{})
+	r.global.SetLevel(level)
+}
+
+func (r *levelRegistry) setRules(rules []componentLevelRule) {
+	r.rules.Store(&rules)
+}
+
+// componentLevel returns the level that applies to logger name through a runtime
+// override or a configured rule.
+func (r *levelRegistry) componentLevel(name string) (zapcore.Level, bool) {
+	if name == "" {
+		return 0, false
+	}
+	if level, ok := (*r.named.Load())[name]; ok {
+		return level, true
+	}
+	for _, rule := range *r.rules.Load() {
+		if rule.matches(name) {
+			return rule.level, true
+		}
+	}
+	return 0, false
+}
+
+func (r *levelRegistry) level(name string) (zapcore.Level, bool) {
+	if level, ok := r.componentLevel(name); ok {
+		return level, true
+	}
+	return r.global.Level(), false
+}
+
+// enabled reports whether an entry at level passes for logger name.
+func (r *levelRegistry) enabled(name string, level zapcore.Level) bool {
+	effective, _ := r.level(name)
+	return level >= effective
+}
+
+// anyEnabled reports whether some logger would accept level. Cores use it before
+// the entry's logger_name is known.
+func (r *levelRegistry) anyEnabled(level zapcore.Level) bool {
+	if level >= r.global.Level() {
+		return true
+	}
+	for _, named := range *r.named.Load() {
+		if level >= named {
+			return true
+		}
+	}
+	for _, rule := range *r.rules.Load() {
+		if level >= rule.level {
+			return true
+		}
+	}
+	return false
+}
+
+// set changes the global level (empty name) or the level of one logger_name. A
+// positive ttl reverts the change once it expires.
+func (r *levelRegistry) set(name string, level zapcore.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setLocked(name, level, ttl)
+}
+
+// step moves the global level by delta, staying between debug and fatal, and
+// returns the new level. Reading and setting under one lock keeps concurrent
+// steps from overwriting each other.
+func (r *levelRegistry) step(delta int, ttl time.Duration) zapcore.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := min(max(r.global.Level()+zapcore.Level(delta), zapcore.DebugLevel), zapcore.FatalLevel)
+	r.setLocked("", next, ttl)
+	return next
+}
+
+func (r *levelRegistry) setLocked(name string, level zapcore.Level, ttl time.Duration) {
+	pending, hasPending := r.timers[name]
+	if hasPending {
+		pending.timer.Stop()
+		delete(r.timers, name)
+	}
+
+	if ttl > 0 {
+		revert := &levelTimer{}
+		if hasPending {
+			revert.previous, revert.hadPrevious = pending.previous, pending.hadPrevious
+		} else {
+			revert.previous, revert.hadPrevious = r.currentLocked(name)
+		}
+		revert.timer = time.AfterFunc(ttl, func() { r.revert(name, revert) })
+		r.timers[name] = revert
+	}
+
+	r.applyLocked(name, level, true)
+}
+
+func (r *levelRegistry) revert(name string, revert *levelTimer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timers[name] != revert {
+		return
+	}
+	delete(r.timers, name)
+	r.applyLocked(name, revert.previous, revert.hadPrevious)
+}
+
+func (r *levelRegistry) clear(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if pending, ok := r.timers[name]; ok {
+		pending.timer.Stop()
+		delete(r.timers, name)
+	}
+	r.applyLocked(name, 0, false)
+}
+
+func (r *levelRegistry) currentLocked(name string) (zapcore.Level, bool) {
+	if name == "" {
+		return r.global.Level(), true
+	}
+	level, ok := (*r.named.Load())[name]
+	return level, ok
+}
+
+func (r *levelRegistry) applyLocked(name string, level zapcore.Level, present bool) {
+	if name == "" {
+		r.global.SetLevel(level)
+		return
+	}
+
+	current := *r.named.Load()
+	updated := make(map[string]zapcore.Level, len(current)+1)
+	for k, v := range current {
+		updated[k] = v
+	}
+	if present {
+		updated[name] = level
+	} else {
+		delete(updated, name)
+	}
+	r.named.Store(&updated)
+}
+
+// AtomicLevel returns the global level shared by the cores built by Init.
+func AtomicLevel() zap.AtomicLevel {
+	return Default().AtomicLevel()
+}
+
+func GetLevel() string {
+	return Default().GetLevel()
+}
+
+// SetLevel changes the global level of every logger built by Init.
+func SetLevel(level string) error {
+	return Default().SetLevel(level)
+}
+
+// SetLevelWithTTL changes the global level and reverts it after ttl. A zero ttl
+// makes the change permanent.
+func SetLevelWithTTL(level string, ttl time.Duration) error {
+	return Default().SetLevelWithTTL(level, ttl)
+}
+
+// GetLoggerLevel returns the effective level of entries tagged with logger_name.
+func GetLoggerLevel(name string) string {
+	return Default().GetLoggerLevel(name)
+}
+
+// SetLoggerLevel overrides the level of entries tagged with logger_name.
+func SetLoggerLevel(name string, level string) error {
+	return Default().SetLoggerLevel(name, level)
+}
+
+func SetLoggerLevelWithTTL(name string, level string, ttl time.Duration) error {
+	return Default().SetLoggerLevelWithTTL(name, level, ttl)
+}
+
+// ResetLoggerLevel removes the override for logger_name so it follows the
+// configured component levels or the global level.
+func ResetLoggerLevel(name string) {
+	Default().ResetLoggerLevel(name)
+}
+
+func (l *Logger) AtomicLevel() zap.AtomicLevel {
+	return l.levels.global
+}
+
+func (l *Logger) GetLevel() string {
+	return l.levels.global.Level().String()
+}
+
+func (l *Logger) SetLevel(level string) error {
+	return l.SetLevelWithTTL(level, 0)
+}
+
+func (l *Logger) SetLevelWithTTL(level string, ttl time.Duration) error {
+	return l.SetLoggerLevelWithTTL("", level, ttl)
+}
+
+func (l *Logger) GetLoggerLevel(name string) string {
+	level, _ := l.levels.level(name)
+	return level.String()
+}
+
+func (l *Logger) SetLoggerLevel(name string, level string) error {
+	return l.SetLoggerLevelWithTTL(name, level, 0)
+}
+
+func (l *Logger) SetLoggerLevelWithTTL(name string, level string, ttl time.Duration) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.set(name, parsed, ttl)
+	return nil
+}
+
+func (l *Logger) ResetLoggerLevel(name string) {
+	l.levels.clear(name)
+}
+
+// levelCore gates entries on the registry instead of a fixed level. The
+// logger_name field, set through With or per entry, selects the override.
+type levelCore struct {
+	zapcore.Core
+	name   string
+	levels *levelRegistry
+}
+
+func newLevelCore(core zapcore.Core, levels *levelRegistry) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	if c.name != "" {
+		return c.levels.enabled(c.name, level)
+	}
+	return c.levels.anyEnabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	name := c.name
+	if fieldName, ok := loggerNameField(fields); ok {
+		name = fieldName
+	}
+	return &levelCore{Core: c.Core.With(fields), name: name, levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *levelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	name := c.name
+	if fieldName, ok := loggerNameField(fields); ok {
+		name = fieldName
+	}
+	if !c.levels.enabled(name, entry.Level) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
+
+func loggerNameField(fields []zapcore.Field) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == "logger_name" && fields[i].Type == zapcore.StringType {
+			return fields[i].String, true
+		}
+	}
+	return "", false
+}
+
+type levelRequest struct {
+	Level      string `json:"level"`
+	LoggerName string `json:"logger_name,omitempty"`
+	TTL        string `json:"ttl,omitempty"`
+}
+
+type levelResponse struct {
+	Level      string `json:"level"`
+	LoggerName string `json:"logger_name,omitempty"`
+}
+
+// LevelHandler serves the current level on GET and changes it on PUT with a body
+// such as {"level":"debug","logger_name":"canonical","ttl":"5m"}. GET accepts a
+// logger_name query parameter. It follows whichever Logger Init installed last.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Default().LevelHandler().ServeHTTP(w, r)
+	})
+}
+
+// LevelHandler is the package-level LevelHandler for this Logger.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+			name := r.URL.Query().Get("logger_name")
+			writeLevelJSON(w, http.StatusOK, levelResponse{Level: l.GetLoggerLevel(name), LoggerName: name})
+		case http.MethodPut:
+			var request levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+				return
+			}
+
+			var ttl time.Duration
+			if request.TTL != "" {
+				parsed, err := time.ParseDuration(request.TTL)
+				if err != nil || parsed < 0 {
+					writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid ttl %q", request.TTL)})
+					return
+				}
+				ttl = parsed
+			}
+
+			if err := l.SetLoggerLevelWithTTL(request.LoggerName, request.Level, ttl); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeLevelJSON(w, http.StatusOK, levelResponse{Level: l.GetLoggerLevel(request.LoggerName), LoggerName: request.LoggerName})
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, body any) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// stepLevel moves the global level by delta, staying between debug and fatal.
+func stepLevel(delta int, ttl time.Duration) zapcore.Level {
+	return Default().levels.step(delta, ttl)
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// WatchLevelSignals makes SIGUSR1 raise verbosity one level (info -> debug) and
+// SIGUSR2 lower it one level. With a positive ttl each change reverts after ttl.
+// Call the returned function to stop watching.
+func WatchLevelSignals(ttl time.Duration) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				delta := 1
+				if sig == syscall.SIGUSR1 {
+					delta = -1
+				}
+				level := stepLevel(delta, ttl)
+				slog.InfoContext(context.Background(), "log level changed by signal",
+					slog.String("signal", sig.String()),
+					slog.String("level", level.String()),
+					slog.String("ttl", ttl.String()),
+				)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		<-exited
+	}
+}
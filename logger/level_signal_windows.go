@@ -0,0 +1,8 @@
+package logger
+
+import "time"
+
+// WatchLevelSignals is a no-op on Windows, which has no SIGUSR1/SIGUSR2.
+func WatchLevelSignals(_ time.Duration) func() {
+	return func() {}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	"github.com/go-slog/otelslog"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -21,32 +23,64 @@ var Env, ServiceName, Version string
 
 type Field = zap.Field
 
+// Config is consumed by Init. config.LogConfig is an alias, so the same struct is
+// loaded from YAML, JSON or TOML and the environment.
 type Config struct {
-	Env         string
-	ServiceName string
-	Level       string
-	UseJSON     bool
-	FileEnabled bool
-	FilePath    string
-	FileSize    int
-	MaxAge      int
-	MaxBackups  int
+	Env         string `yaml:"env" json:"env" mapstructure:"env"`
+	ServiceName string `yaml:"serviceName" json:"serviceName" mapstructure:"serviceName"`
+	Level       string `yaml:"level" json:"level" mapstructure:"level"`
+	UseJSON     bool   `yaml:"useJsonEncoder" json:"useJsonEncoder" mapstructure:"useJsonEncoder"`
+	FileEnabled bool   `yaml:"fileEnabled" json:"fileEnabled" mapstructure:"fileEnabled"`
+	FilePath    string `yaml:"filePath" json:"filePath" mapstructure:"filePath"`
+	FileSize    int    `yaml:"fileSize" json:"fileSize" mapstructure:"fileSize"`
+	MaxAge      int    `yaml:"maxAge" json:"maxAge" mapstructure:"maxAge"`
+	MaxBackups  int    `yaml:"maxBackups" json:"maxBackups" mapstructure:"maxBackups"`
+	// Redaction overrides DefaultRedactionConfig when set.
+	Redaction *RedactionConfig `yaml:"redaction" json:"redaction,omitempty" mapstructure:"redaction"`
+	// Body limits what CanonicalLogger writes for request and response bodies.
+	Body BodyLogConfig `yaml:"body" json:"body" mapstructure:"body"`
+	// Sampling drops repetitive entries and successful canonical logs on noisy paths.
+	Sampling SamplingConfig `yaml:"sampling" json:"sampling" mapstructure:"sampling"`
+	// Async writes entries from a background goroutine instead of the caller's.
+	Async AsyncConfig `yaml:"async" json:"async" mapstructure:"async"`
+	// Outputs replaces the stdout and file outputs chosen by UseJSON and
+	// FileEnabled with a list of sinks, each with its own encoder and level.
+	Outputs []OutputConfig `yaml:"outputs" json:"outputs,omitempty" mapstructure:"outputs"`
+	// ComponentLevels maps a logger_name, or a glob such as "svc.payment.*", to a
+	// level that replaces Level for matching entries.
+	ComponentLevels map[string]string `yaml:"componentLevels" json:"componentLevels,omitempty" mapstructure:"componentLevels"`
+	// Tracing makes the middlewares propagate trace headers and start spans.
+	Tracing TracingConfig `yaml:"tracing" json:"tracing" mapstructure:"tracing"`
 }
 
+// Init builds the default Logger from config and points Log, Slog, the Datadog
+// globals and slog's default logger at it. The Logger it replaces flushes and
+// closes its files, queues and outputs, waiting at most DefaultShutdownTimeout;
+// loggers taken from it before the call keep writing to stdout or the writer
+// given to New.
 func Init(config Config) *slog.Logger {
 	m.Lock()
 	defer m.Unlock()
 
-	Env = getEnvOrDefault("DD_ENV", config.Env)
-	ServiceName = getEnvOrDefault("DD_SERVICE", config.ServiceName)
-	Version = getEnvOrDefault("DD_VERSION", "unknown")
+	instance := New(WithConfig(config))
+	previous := defaultLogger.Swap(instance)
 
-	// Create the zap logger
-	zapLogger, slogLogger := newZapLogger(config)
-	Log = zapLogger
-	Slog = slogLogger
+	Env = instance.env
+	ServiceName = instance.serviceName
+	Version = instance.version
+	Log = instance.zap
+	Slog = instance.slog
 	slog.SetDefault(Slog)
 	CompileCanonicalLogTemplate()
+
+	if previous != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+		if err := previous.release(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: releasing the replaced logger: %v\n", err)
+		}
+		cancel()
+	}
+
 	slog.InfoContext(context.Background(), "Logger initialized")
 
 	return Slog
@@ -54,8 +88,14 @@ func Init(config Config) *slog.Logger {
 
 var _ slog.Handler = Handler{}
 
+// Handler adds the context attributes, trace and Datadog fields and routes
+// levels on logger_name, taken from WithAttrs or from the record itself.
+// Handlers built by New use that Logger's fields and levels; the exported
+// constructors use the defaults.
 type Handler struct {
 	handler slog.Handler
+	name    string
+	owner   *Logger
 }
 
 func NewOtelHandler(handler slog.Handler) Handler {
@@ -66,16 +106,46 @@ func NewHandler(handler slog.Handler) Handler {
 	return Handler{handler: handler}
 }
 
+func (h Handler) levels() *levelRegistry {
+	if h.owner != nil {
+		return h.owner.levels
+	}
+	return Default().levels
+}
+
 func (h Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if componentLevel, ok := h.levels().componentLevel(h.name); ok {
+		return level >= slogLevel(componentLevel)
+	}
 	return h.handler.Enabled(ctx, level)
 }
 
 func (h Handler) Handle(ctx context.Context, record slog.Record) error {
-	AddDDFields(ctx, &record)
+	name := h.name
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "logger_name" {
+			name = attr.Value.String()
+		}
+		return true
+	})
+	if componentLevel, ok := h.levels().componentLevel(name); ok && record.Level < slogLevel(componentLevel) {
+		return nil
+	}
+
+	addContextAttrs(ctx, &record)
+	if h.owner != nil {
+		addDDFields(ctx, &record, h.owner.env, h.owner.serviceName, h.owner.version)
+	} else {
+		AddDDFields(ctx, &record)
+	}
 	return h.handler.Handle(ctx, record)
 }
 
 func AddDDFields(ctx context.Context, record *slog.Record) {
+	addDDFields(ctx, record, Env, ServiceName, Version)
+}
+
+func addDDFields(ctx context.Context, record *slog.Record, env, serviceName, version string) {
 	spanCtx := trace.SpanContextFromContext(ctx)
 	var traceID, spanID string
 
@@ -90,27 +160,41 @@ func AddDDFields(ctx context.Context, record *slog.Record) {
 	}
 
 	record.AddAttrs(slog.Group("dd",
-		slog.String("env", Env),
-		slog.String("service", ServiceName),
+		slog.String("env", env),
+		slog.String("service", serviceName),
 		slog.String("trace_id", traceID),
 		slog.String("span_id", spanID),
-		slog.String("version", Version),
+		slog.String("version", version),
 	))
 }
 
 func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return Handler{h.handler.WithAttrs(attrs)}
+	name := h.name
+	for _, attr := range attrs {
+		if attr.Key == "logger_name" {
+			name = attr.Value.String()
+		}
+	}
+	return Handler{handler: h.handler.WithAttrs(attrs), name: name, owner: h.owner}
+}
+
+// slogLevel converts a zap level; zap levels are one step apart where slog uses four.
+func slogLevel(level zapcore.Level) slog.Level {
+	return slog.Level(int(level) * 4)
 }
 
 func (h Handler) WithGroup(name string) slog.Handler {
-	return h.handler.WithGroup(name)
+	return Handler{handler: h.handler.WithGroup(name), name: h.name, owner: h.owner}
 }
 
-func getDefaultConfig(env string) Config {
+// DefaultConfig returns the settings used for env before any file, environment
+// variable or option is applied. Local and development environments log at debug
+// level to the console; everything else logs JSON at info level.
+func DefaultConfig(env string) Config {
 	if env == "local" || env == "development" {
 		return Config{
 			Env:         env,
-			ServiceName: ServiceName,
+			ServiceName: "blogger-service",
 			Level:       "debug",
 			UseJSON:     false,
 			FileEnabled: false,
@@ -123,7 +207,7 @@ func getDefaultConfig(env string) Config {
 
 	return Config{
 		Env:         env,
-		ServiceName: ServiceName,
+		ServiceName: "blogger-service",
 		Level:       "info",
 		UseJSON:     true,
 		FileEnabled: false,
@@ -134,6 +218,8 @@ func getDefaultConfig(env string) Config {
 	}
 }
 
+// Pathfinder logs through the logger stored in ctx by WithContext, or the
+// default one.
 type Pathfinder struct {
 	svc string
 }
@@ -142,12 +228,30 @@ func NewPathfinder(svc string) Pathfinder {
 	return Pathfinder{svc: svc}
 }
 
+// LoggerName is the logger_name attached to every entry, "svc.<dotted path>", so
+// ComponentLevels rules such as "svc.payment.*" can target a service.
+func (p Pathfinder) LoggerName() string {
+	return "svc." + p.svc
+}
+
+func (p Pathfinder) DebugContext(ctx context.Context, msg string, fields ...any) {
+	FromContext(ctx).DebugContext(ctx, "[service]["+p.svc+"] "+msg, p.fields(fields)...)
+}
+
 func (p Pathfinder) InfoContext(ctx context.Context, msg string, fields ...any) {
-	slog.InfoContext(ctx, "[service]["+p.svc+"] "+msg, fields...)
+	FromContext(ctx).InfoContext(ctx, "[service]["+p.svc+"] "+msg, p.fields(fields)...)
+}
+
+func (p Pathfinder) WarnContext(ctx context.Context, msg string, fields ...any) {
+	FromContext(ctx).WarnContext(ctx, "[service]["+p.svc+"] "+msg, p.fields(fields)...)
 }
 
 func (p Pathfinder) ErrorContext(ctx context.Context, msg string, fields ...any) {
-	slog.ErrorContext(ctx, "[service]["+p.svc+"] "+msg, fields...)
+	FromContext(ctx).ErrorContext(ctx, "[service]["+p.svc+"] "+msg, p.fields(fields)...)
+}
+
+func (p Pathfinder) fields(fields []any) []any {
+	return append([]any{slog.String("logger_name", p.LoggerName())}, fields...)
 }
 
 func (p Pathfinder) NewPathfinder(svc string) Pathfinder {
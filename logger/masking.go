@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pawatthir/blogger/bloggerpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// MaskSensitiveFields masks the entries of data, the JSON form of v, that are
+// marked sensitive. Proto messages are walked through their descriptors and honor
+// the (blogger.sensitive) field option; any struct additionally honors the
+// `sensitive:"true"` struct tag. Nested messages, repeated fields and maps are
+// followed in both cases.
+func MaskSensitiveFields(v any, data map[string]interface{}) {
+	if v == nil || data == nil {
+		return
+	}
+	if message, ok := v.(proto.Message); ok {
+		maskProtoMessage(message.ProtoReflect(), data)
+	}
+	maskStruct(reflect.ValueOf(v), data)
+}
+
+// MaskSensitiveJSON applies MaskSensitiveFields to a JSON object body and returns
+// the re-encoded result. Bodies that are not JSON objects are returned unchanged.
+func MaskSensitiveJSON(v any, body []byte) []byte {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return body
+	}
+
+	MaskSensitiveFields(v, data)
+
+	masked, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+func isSensitiveField(fd protoreflect.FieldDescriptor) bool {
+	options, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || options == nil {
+		return false
+	}
+	sensitive, _ := proto.GetExtension(options, bloggerpb.E_Sensitive).(bool)
+	return sensitive
+}
+
+func maskProtoMessage(message protoreflect.Message, data map[string]interface{}) {
+	if !message.IsValid() {
+		return
+	}
+
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		key := fd.JSONName()
+		if _, exists := data[key]; !exists {
+			key = string(fd.Name())
+			if _, exists := data[key]; !exists {
+				continue
+			}
+		}
+
+		if isSensitiveField(fd) {
+			data[key] = applyStrategyToValue(RedactPartial, data[key])
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() != protoreflect.MessageKind {
+				continue
+			}
+			entries, ok := data[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			message.Get(fd).Map().Range(func(mapKey protoreflect.MapKey, value protoreflect.Value) bool {
+				if entry, ok := entries[mapKey.String()].(map[string]interface{}); ok {
+					maskProtoMessage(value.Message(), entry)
+				}
+				return true
+			})
+		case fd.IsList():
+			if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+				continue
+			}
+			items, ok := data[key].([]interface{})
+			if !ok {
+				continue
+			}
+			list := message.Get(fd).List()
+			for j := 0; j < list.Len() && j < len(items); j++ {
+				if item, ok := items[j].(map[string]interface{}); ok {
+					maskProtoMessage(list.Get(j).Message(), item)
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			if nested, ok := data[key].(map[string]interface{}); ok && message.Has(fd) {
+				maskProtoMessage(message.Get(fd).Message(), nested)
+			}
+		}
+	}
+}
+
+func maskStruct(value reflect.Value, data map[string]interface{}) {
+	value = indirect(value)
+	if !value.IsValid() || value.Kind() != reflect.Struct {
+		return
+	}
+
+	typeOf := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := typeOf.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+
+		// Proto oneof wrappers and embedded structs are flattened into the parent object.
+		if field.Type.Kind() == reflect.Interface && field.Tag.Get("protobuf_oneof") != "" {
+			maskStruct(fieldValue.Elem(), data)
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			maskStruct(fieldValue, data)
+			continue
+		}
+
+		key, ok := lookupJSONKey(field, data)
+		if !ok {
+			continue
+		}
+
+		if sensitiveTag, ok := field.Tag.Lookup("sensitive"); ok && sensitiveTag == "true" {
+			data[key] = applyStrategyToValue(RedactPartial, data[key])
+			continue
+		}
+
+		maskValue(fieldValue, data[key])
+	}
+}
+
+func maskValue(value reflect.Value, data interface{}) {
+	value = indirect(value)
+	if !value.IsValid() {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if nested, ok := data.(map[string]interface{}); ok {
+			maskStruct(value, nested)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := data.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < value.Len() && i < len(items); i++ {
+			maskValue(value.Index(i), items[i])
+		}
+	case reflect.Map:
+		entries, ok := data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if entry, exists := entries[fmt.Sprint(iter.Key().Interface())]; exists {
+				maskValue(iter.Value(), entry)
+			}
+		}
+	}
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+func lookupJSONKey(field reflect.StructField, data map[string]interface{}) (string, bool) {
+	candidates := []string{}
+
+	if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+		name := jsonTag
+		if commaIdx := strings.Index(jsonTag, ","); commaIdx > -1 {
+			name = jsonTag[:commaIdx]
+		}
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			candidates = append(candidates, name)
+		}
+	}
+
+	for _, part := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "json=") {
+			candidates = append(candidates, strings.TrimPrefix(part, "json="))
+		} else if strings.HasPrefix(part, "name=") {
+			candidates = append(candidates, strings.TrimPrefix(part, "name="))
+		}
+	}
+
+	candidates = append(candidates, field.Name)
+
+	for _, candidate := range candidates {
+		if _, exists := data[candidate]; exists {
+			return candidate, true
+		}
+	}
+	return "", false
+}
@@ -22,19 +22,20 @@ func (pl *PGXLogger) Log(ctx context.Context, level tracelog.LogLevel, msg strin
 		fields = append(fields, slog.Any(k, v))
 	}
 
+	slogger := FromContext(ctx)
 	switch level {
 	case tracelog.LogLevelTrace:
-		slog.DebugContext(ctx, msg, fields...)
+		slogger.DebugContext(ctx, msg, fields...)
 	case tracelog.LogLevelDebug:
-		slog.DebugContext(ctx, msg, fields...)
+		slogger.DebugContext(ctx, msg, fields...)
 	case tracelog.LogLevelInfo:
-		slog.InfoContext(ctx, msg, fields...)
+		slogger.InfoContext(ctx, msg, fields...)
 	case tracelog.LogLevelWarn:
-		slog.WarnContext(ctx, msg, fields...)
+		slogger.WarnContext(ctx, msg, fields...)
 	case tracelog.LogLevelError:
-		slog.ErrorContext(ctx, msg, fields...)
+		slogger.ErrorContext(ctx, msg, fields...)
 	default:
-		slog.ErrorContext(ctx, msg, fields...)
+		slogger.ErrorContext(ctx, msg, fields...)
 	}
 }
 
@@ -0,0 +1,407 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RedactStrategy string
+
+const (
+	// RedactDrop removes a matched field; matched values become "[REDACTED]".
+	RedactDrop RedactStrategy = "drop"
+	// RedactMask replaces the whole value with "*****".
+	RedactMask RedactStrategy = "mask"
+	// RedactHash replaces the value with a short sha256 digest so equal values stay correlatable.
+	RedactHash RedactStrategy = "hash"
+	// RedactPartial keeps the first and last characters, e.g. "s*****3".
+	RedactPartial RedactStrategy = "partial"
+)
+
+const redactedValue = "REDACTED"
+
+// PathRule redacts a whole request/response when the HTTP path or gRPC method
+// contains Pattern (case-insensitive).
+type PathRule struct {
+	Pattern  string         `yaml:"pattern" json:"pattern" mapstructure:"pattern"`
+	Strategy RedactStrategy `yaml:"strategy" json:"strategy" mapstructure:"strategy"`
+}
+
+// FieldRule redacts JSON fields by path. "$.user.password" only matches from the
+// root, while "*.card_number" or "card_number" match the key at any depth. A "*"
+// segment matches any single key and array indices such as "[*]" are ignored.
+type FieldRule struct {
+	Path     string         `yaml:"path" json:"path" mapstructure:"path"`
+	Strategy RedactStrategy `yaml:"strategy" json:"strategy" mapstructure:"strategy"`
+}
+
+// ValueRule redacts substrings of any string value matching Pattern, which is
+// either a regular expression or the name of an entry in BuiltinValuePatterns.
+type ValueRule struct {
+	Name     string         `yaml:"name" json:"name" mapstructure:"name"`
+	Pattern  string         `yaml:"pattern" json:"pattern" mapstructure:"pattern"`
+	Strategy RedactStrategy `yaml:"strategy" json:"strategy" mapstructure:"strategy"`
+}
+
+type RedactionConfig struct {
+	PathRules  []PathRule  `yaml:"pathRules" json:"pathRules" mapstructure:"pathRules"`
+	FieldRules []FieldRule `yaml:"fieldRules" json:"fieldRules" mapstructure:"fieldRules"`
+	ValueRules []ValueRule `yaml:"valueRules" json:"valueRules" mapstructure:"valueRules"`
+}
+
+var BuiltinValuePatterns = map[string]string{
+	"email":            `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	"credit_card":      `\b(?:\d[ \-]?){12,18}\d\b`,
+	"thai_national_id": `\b\d-?\d{4}-?\d{5}-?\d{2}-?\d\b`,
+}
+
+var builtinValueValidators = map[string]func(string) bool{
+	"credit_card":      luhnValid,
+	"thai_national_id": thaiNationalIDValid,
+}
+
+// DefaultRedactionConfig blanks whole payloads on paths containing one of the
+// DenyPatterns, as read when it is called, and masks common secret keys.
+func DefaultRedactionConfig() RedactionConfig {
+	pathRules := make([]PathRule, 0, len(DenyPatterns))
+	for _, pattern := range DenyPatterns {
+		pathRules = append(pathRules, PathRule{Pattern: pattern, Strategy: RedactDrop})
+	}
+
+	fieldRules := []FieldRule{}
+	for _, key := range []string{
+		"password", "passwd", "secret", "token", "access_token", "refresh_token",
+		"api_key", "apikey", "authorization", "otp", "pin", "cvv", "card_number",
+	} {
+		fieldRules = append(fieldRules, FieldRule{Path: "*." + key, Strategy: RedactMask})
+	}
+
+	return RedactionConfig{
+		PathRules:  pathRules,
+		FieldRules: fieldRules,
+		ValueRules: []ValueRule{
+			{Name: "credit_card", Pattern: "credit_card", Strategy: RedactPartial},
+			{Name: "thai_national_id", Pattern: "thai_national_id", Strategy: RedactPartial},
+		},
+	}
+}
+
+type Redactor struct {
+	pathRules  []PathRule
+	fieldRules []compiledFieldRule
+	valueRules []compiledValueRule
+}
+
+type compiledFieldRule struct {
+	segments []string
+	anchored bool
+	strategy RedactStrategy
+}
+
+type compiledValueRule struct {
+	name     string
+	pattern  *regexp.Regexp
+	validate func(string) bool
+	strategy RedactStrategy
+}
+
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, rule := range config.PathRules {
+		if err := validateStrategy(rule.Strategy); err != nil {
+			return nil, fmt.Errorf("path rule %q: %w", rule.Pattern, err)
+		}
+		r.pathRules = append(r.pathRules, PathRule{Pattern: strings.ToLower(rule.Pattern), Strategy: rule.Strategy})
+	}
+
+	for _, rule := range config.FieldRules {
+		if err := validateStrategy(rule.Strategy); err != nil {
+			return nil, fmt.Errorf("field rule %q: %w", rule.Path, err)
+		}
+		r.fieldRules = append(r.fieldRules, compileFieldRule(rule))
+	}
+
+	for _, rule := range config.ValueRules {
+		if err := validateStrategy(rule.Strategy); err != nil {
+			return nil, fmt.Errorf("value rule %q: %w", rule.Name, err)
+		}
+
+		expr := rule.Pattern
+		if builtin, ok := BuiltinValuePatterns[rule.Pattern]; ok {
+			expr = builtin
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("value rule %q: %w", rule.Name, err)
+		}
+
+		r.valueRules = append(r.valueRules, compiledValueRule{
+			name:     rule.Name,
+			pattern:  pattern,
+			validate: builtinValueValidators[rule.Pattern],
+			strategy: rule.Strategy,
+		})
+	}
+
+	return r, nil
+}
+
+func NewDefaultRedactor() *Redactor {
+	r, err := NewRedactor(DefaultRedactionConfig())
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// SetRedactor replaces the redactor used by CanonicalLogger. A nil redactor
+// restores the defaults.
+func SetRedactor(r *Redactor) {
+	Default().SetRedactor(r)
+}
+
+func GetRedactor() *Redactor {
+	return Default().Redactor()
+}
+
+func (l *Logger) SetRedactor(r *Redactor) {
+	if r == nil {
+		r = NewDefaultRedactor()
+	}
+	l.redactor.Store(r)
+}
+
+func (l *Logger) Redactor() *Redactor {
+	return l.redactor.Load()
+}
+
+func validateStrategy(strategy RedactStrategy) error {
+	switch strategy {
+	case RedactDrop, RedactMask, RedactHash, RedactPartial:
+		return nil
+	default:
+		return fmt.Errorf("unknown redaction strategy %q", strategy)
+	}
+}
+
+func compileFieldRule(rule FieldRule) compiledFieldRule {
+	path := strings.TrimSpace(rule.Path)
+	anchored := false
+	if strings.HasPrefix(path, "$") {
+		anchored = true
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	} else {
+		path = strings.TrimPrefix(path, "*.")
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		if idx := strings.Index(segment, "["); idx > -1 {
+			segment = segment[:idx]
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return compiledFieldRule{segments: segments, anchored: anchored, strategy: rule.Strategy}
+}
+
+func (rule compiledFieldRule) matches(path []string) bool {
+	if len(rule.segments) == 0 || len(path) < len(rule.segments) {
+		return false
+	}
+	if rule.anchored && len(path) != len(rule.segments) {
+		return false
+	}
+
+	offset := len(path) - len(rule.segments)
+	for i, segment := range rule.segments {
+		if segment != "*" && !strings.EqualFold(segment, path[offset+i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchPath reports the path rule matching logKey, if any.
+func (r *Redactor) MatchPath(logKey string) (PathRule, bool) {
+	logKeyLower := strings.ToLower(logKey)
+	for _, rule := range r.pathRules {
+		if strings.Contains(logKeyLower, rule.Pattern) {
+			return rule, true
+		}
+	}
+	return PathRule{}, false
+}
+
+// RedactBody returns the loggable form of a raw request or response body for the
+// given path: a redacted JSON object or array when the body parses as one,
+// otherwise the body as a string with value rules applied.
+func (r *Redactor) RedactBody(logKey string, body []byte) any {
+	if rule, ok := r.MatchPath(logKey); ok {
+		if rule.Strategy == RedactDrop {
+			return redactedValue
+		}
+		return applyStrategy(rule.Strategy, string(body))
+	}
+	return r.RedactPayload(body)
+}
+
+// RedactPayload is RedactBody without the path rules, for bodies that have no
+// path of their own to match, such as those of outgoing calls.
+func (r *Redactor) RedactPayload(body []byte) any {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return r.redactString(string(body))
+	}
+	switch decoded.(type) {
+	case map[string]interface{}, []interface{}:
+		return r.redactValue(decoded, nil)
+	default:
+		return r.redactString(string(body))
+	}
+}
+
+// RedactMap applies field and value rules to data in place.
+func (r *Redactor) RedactMap(data map[string]interface{}) {
+	r.redactObject(data, nil)
+}
+
+func (r *Redactor) redactObject(data map[string]interface{}, path []string) {
+	for key, value := range data {
+		fieldPath := append(path[:len(path):len(path)], key)
+
+		if rule, ok := r.matchField(fieldPath); ok {
+			if rule.strategy == RedactDrop {
+				delete(data, key)
+			} else {
+				data[key] = applyStrategyToValue(rule.strategy, value)
+			}
+			continue
+		}
+
+		data[key] = r.redactValue(value, fieldPath)
+	}
+}
+
+func (r *Redactor) redactValue(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		r.redactObject(v, path)
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item, path)
+		}
+		return v
+	case string:
+		return r.redactString(v)
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) matchField(path []string) (compiledFieldRule, bool) {
+	for _, rule := range r.fieldRules {
+		if rule.matches(path) {
+			return rule, true
+		}
+	}
+	return compiledFieldRule{}, false
+}
+
+func (r *Redactor) redactString(s string) string {
+	for _, rule := range r.valueRules {
+		s = rule.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.validate != nil && !rule.validate(match) {
+				return match
+			}
+			if rule.strategy == RedactDrop {
+				return "[" + redactedValue + "]"
+			}
+			return applyStrategy(rule.strategy, match)
+		})
+	}
+	return s
+}
+
+func applyStrategyToValue(strategy RedactStrategy, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return applyStrategy(strategy, s)
+	}
+	if strategy == RedactHash {
+		raw, _ := json.Marshal(value)
+		return applyStrategy(strategy, string(raw))
+	}
+	return applyStrategy(RedactMask, "")
+}
+
+func applyStrategy(strategy RedactStrategy, s string) string {
+	switch strategy {
+	case RedactHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:])[:16]
+	case RedactPartial:
+		if len(s) >= 2 {
+			return s[0:1] + "*****" + s[len(s)-1:]
+		} else if len(s) == 1 {
+			return s + "*****"
+		}
+		return s
+	case RedactDrop:
+		return ""
+	default:
+		return "*****"
+	}
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func luhnValid(s string) bool {
+	digits := digitsOnly(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func thaiNationalIDValid(s string) bool {
+	digits := digitsOnly(s)
+	if len(digits) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]-'0') * (13 - i)
+	}
+	return (11-sum%11)%10 == int(digits[12]-'0')
+}
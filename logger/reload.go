@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// RejectedChange is a configuration change Reconfigure could not apply to a
+// running logger.
+type RejectedChange struct {
+	Field  string
+	Reason string
+}
+
+func (c RejectedChange) String() string {
+	return c.Field + ": " + c.Reason
+}
+
+// Reconfigure applies the safe parts of config to the running logger: level,
+// component levels, sampling, redaction rules, body limits and file rotation
+// settings. Changes that need a new logger, such as the encoder or the service
+// name, are returned as rejected and left as they were. An invalid config is
+// rejected as a whole and nothing is applied. It changes the Logger built by
+// the last Init.
+func Reconfigure(config Config) ([]RejectedChange, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	return Default().Reconfigure(config)
+}
+
+// Reconfigure is the package-level Reconfigure for this Logger.
+func (l *Logger) Reconfigure(config Config) ([]RejectedChange, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.config
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	componentRules, err := compileComponentLevels(config.ComponentLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	var redactor *Redactor
+	if !reflect.DeepEqual(config.Redaction, current.Redaction) {
+		redactor = NewDefaultRedactor()
+		if config.Redaction != nil {
+			redactor, err = NewRedactor(*config.Redaction)
+			if err != nil {
+				return nil, fmt.Errorf("redaction: %w", err)
+			}
+		}
+	}
+
+	var rejected []RejectedChange
+	reject := func(field string, changed bool) {
+		if changed {
+			rejected = append(rejected, RejectedChange{Field: field, Reason: "cannot change on a running logger, restart to apply"})
+		}
+	}
+	reject("env", config.Env != current.Env)
+	reject("serviceName", config.ServiceName != current.ServiceName)
+	reject("useJsonEncoder", config.UseJSON != current.UseJSON)
+	reject("fileEnabled", config.FileEnabled != current.FileEnabled)
+	reject("async", !reflect.DeepEqual(config.Async, current.Async))
+	reject("outputs", !reflect.DeepEqual(config.Outputs, current.Outputs))
+	reject("tracing", !reflect.DeepEqual(config.Tracing, current.Tracing))
+
+	applied := current
+	if config.Level != current.Level {
+		l.levels.set("", getZapLogLevel(config.Level), 0)
+		applied.Level = config.Level
+	}
+	if !reflect.DeepEqual(config.ComponentLevels, current.ComponentLevels) {
+		l.levels.setRules(componentRules)
+		applied.ComponentLevels = config.ComponentLevels
+	}
+	if !reflect.DeepEqual(config.Sampling, current.Sampling) {
+		l.setSampling(config.Sampling)
+		l.startSamplingReporter(nil, config.Sampling)
+		applied.Sampling = config.Sampling
+	}
+	if redactor != nil {
+		l.SetRedactor(redactor)
+		applied.Redaction = config.Redaction
+	}
+	if !reflect.DeepEqual(config.Body, current.Body) {
+		l.SetBodyLogConfig(config.Body)
+		applied.Body = config.Body
+	}
+
+	rotationChanged := config.FilePath != current.FilePath || config.FileSize != current.FileSize ||
+		config.MaxAge != current.MaxAge || config.MaxBackups != current.MaxBackups
+	if rotationChanged {
+		applied.FilePath, applied.FileSize = config.FilePath, config.FileSize
+		applied.MaxAge, applied.MaxBackups = config.MaxAge, config.MaxBackups
+		if l.fileWriter != nil {
+			if err := l.fileWriter.update(applied); err != nil {
+				l.slog.WarnContext(context.Background(), "failed to close previous log file", "error", err)
+			}
+		}
+	}
+
+	l.config = applied
+	return rejected, nil
+}
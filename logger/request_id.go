@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"github.com/google/uuid"
+)
+
+// Where middleware reads and writes the request ID: the HTTP header, and the
+// gRPC metadata key, which gRPC requires in lower case.
+const (
+	RequestIDHeader      = "X-Request-Id"
+	RequestIDMetadataKey = "x-request-id"
+)
+
+// maxRequestIDLength bounds the IDs accepted from callers, so a client cannot
+// put arbitrary payloads into every log line of a request.
+const maxRequestIDLength = 128
+
+// NewRequestID returns a UUIDv7, which sorts by creation time.
+func NewRequestID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// RequestIDOrNew returns id when it is usable as a request ID, that is short
+// and made of printable ASCII, and a new ID otherwise.
+func RequestIDOrNew(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return NewRequestID()
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return NewRequestID()
+		}
+	}
+	return id
+}
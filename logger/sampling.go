@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type SamplingConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	// Initial entries with the same level and message are logged per Tick, then
+	// every Thereafter-th one. Initial == 0 disables this stage.
+	Initial    int           `yaml:"initial" json:"initial" mapstructure:"initial"`
+	Thereafter int           `yaml:"thereafter" json:"thereafter" mapstructure:"thereafter"`
+	Tick       time.Duration `yaml:"tick" json:"tick" mapstructure:"tick"`
+	// Levels maps a level name to the fraction of its entries kept, e.g. {"debug": 0.1}.
+	Levels map[string]float64 `yaml:"levels" json:"levels" mapstructure:"levels"`
+	// Paths sample canonical logs of successful requests; errors are always logged.
+	Paths []PathSamplingRule `yaml:"paths" json:"paths" mapstructure:"paths"`
+	// ReportInterval controls how often the dropped entry counts are logged.
+	ReportInterval time.Duration `yaml:"reportInterval" json:"reportInterval" mapstructure:"reportInterval"`
+}
+
+// PathSamplingRule keeps Rate of the canonical logs whose path contains Pattern
+// (case-insensitive). When StatusCodes is set only those statuses are sampled.
+type PathSamplingRule struct {
+	Pattern     string  `yaml:"pattern" json:"pattern" mapstructure:"pattern"`
+	Rate        float64 `yaml:"rate" json:"rate" mapstructure:"rate"`
+	StatusCodes []int   `yaml:"statusCodes" json:"statusCodes" mapstructure:"statusCodes"`
+}
+
+type SamplingDrops struct {
+	Sampler   uint64
+	LevelRate uint64
+	PathRule  uint64
+}
+
+func (d SamplingDrops) Total() uint64 {
+	return d.Sampler + d.LevelRate + d.PathRule
+}
+
+var (
+	// Totals across every Logger; each Logger also counts its own drops.
+	droppedBySampler   atomic.Uint64
+	droppedByLevelRate atomic.Uint64
+	droppedByPathRule  atomic.Uint64
+)
+
+const samplingCountersPerLevel = 4096
+
+type samplingCounterTable [zapcore.FatalLevel - zapcore.DebugLevel + 1][samplingCountersPerLevel]samplingCounter
+
+type samplingDropCounters struct {
+	sampler   atomic.Uint64
+	levelRate atomic.Uint64
+	pathRule  atomic.Uint64
+}
+
+func (d *samplingDropCounters) load() SamplingDrops {
+	return SamplingDrops{
+		Sampler:   d.sampler.Load(),
+		LevelRate: d.levelRate.Load(),
+		PathRule:  d.pathRule.Load(),
+	}
+}
+
+type samplingReporter struct {
+	mu   sync.Mutex
+	stop chan struct{}
+	core zapcore.Core
+}
+
+type samplingState struct {
+	config   SamplingConfig
+	tick     time.Duration
+	rates    map[zapcore.Level]float64
+	counters *samplingCounterTable
+	drops    *samplingDropCounters
+}
+
+// SamplingDropped returns the number of entries dropped by every Logger since
+// the process started.
+func SamplingDropped() SamplingDrops {
+	return SamplingDrops{
+		Sampler:   droppedBySampler.Load(),
+		LevelRate: droppedByLevelRate.Load(),
+		PathRule:  droppedByPathRule.Load(),
+	}
+}
+
+// SamplingDropped returns the number of entries this Logger dropped.
+func (l *Logger) SamplingDropped() SamplingDrops {
+	return l.drops.load()
+}
+
+// setSampling installs config for the cores of l along with the canonical path
+// rules. Cores read it on every entry so a reload takes effect without
+// rebuilding the logger; a nil state means sampling is disabled.
+func (l *Logger) setSampling(config SamplingConfig) {
+	l.setPathSampling(config)
+	if !config.Enabled {
+		l.sampling.Store(nil)
+		return
+	}
+
+	state := &samplingState{config: config, tick: config.Tick, drops: &l.drops}
+	if state.tick <= 0 {
+		state.tick = time.Second
+	}
+	if len(config.Levels) > 0 {
+		state.rates = map[zapcore.Level]float64{}
+		for name, rate := range config.Levels {
+			state.rates[getZapLogLevel(name)] = rate
+		}
+	}
+	if config.Initial > 0 {
+		// The counter table is large, so it is allocated once and kept across reloads.
+		if l.samplingCounters == nil {
+			l.samplingCounters = &samplingCounterTable{}
+		}
+		state.counters = l.samplingCounters
+	}
+	l.sampling.Store(state)
+}
+
+// samplingCore drops entries by level rate, then logs the first Initial entries
+// with the same level and message per tick and every Thereafter-th one after,
+// the same policy as zapcore.NewSamplerWithOptions. It is the outermost core,
+// so it also drops everything once the Logger is shut down.
+type samplingCore struct {
+	zapcore.Core
+	owner *Logger
+}
+
+func newSamplingCore(core zapcore.Core, owner *Logger) zapcore.Core {
+	return &samplingCore{Core: core, owner: owner}
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), owner: c.owner}
+}
+
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.owner.closed.Load() || !c.Enabled(entry.Level) {
+		return checked
+	}
+	if state := c.owner.sampling.Load(); state != nil && !state.keep(entry) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+func (s *samplingState) keep(entry zapcore.Entry) bool {
+	if rate, ok := s.rates[entry.Level]; ok && rate < 1 && rand.Float64() >= rate {
+		s.drops.levelRate.Add(1)
+		droppedByLevelRate.Add(1)
+		return false
+	}
+
+	if s.counters == nil || entry.Level < zapcore.DebugLevel || entry.Level > zapcore.FatalLevel {
+		return true
+	}
+	counter := &s.counters[entry.Level-zapcore.DebugLevel][fnv32a(entry.Message)%samplingCountersPerLevel]
+	n := counter.inc(entry.Time, s.tick)
+	if n <= uint64(s.config.Initial) {
+		return true
+	}
+	if s.config.Thereafter > 0 && (n-uint64(s.config.Initial))%uint64(s.config.Thereafter) == 0 {
+		return true
+	}
+	s.drops.sampler.Add(1)
+	droppedBySampler.Add(1)
+	return false
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func (c *samplingCounter) inc(t time.Time, tick time.Duration) uint64 {
+	now := t.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick.Nanoseconds()) {
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+	return hash
+}
+
+func (l *Logger) setPathSampling(config SamplingConfig) {
+	var rules []PathSamplingRule
+	if config.Enabled {
+		for _, rule := range config.Paths {
+			rule.Pattern = strings.ToLower(rule.Pattern)
+			rules = append(rules, rule)
+		}
+	}
+	l.pathSampling.Store(&rules)
+}
+
+// sampleCanonical reports whether a canonical log should be written. Errors are
+// always kept.
+func (l *Logger) sampleCanonical(logKey string, status int, isError bool) bool {
+	if isError {
+		return true
+	}
+	rules := l.pathSampling.Load()
+	if rules == nil {
+		return true
+	}
+
+	logKeyLower := strings.ToLower(logKey)
+	for _, rule := range *rules {
+		if !strings.Contains(logKeyLower, rule.Pattern) {
+			continue
+		}
+		if len(rule.StatusCodes) > 0 && !containsStatus(rule.StatusCodes, status) {
+			continue
+		}
+		if rule.Rate >= 1 || rand.Float64() < rule.Rate {
+			return true
+		}
+		l.drops.pathRule.Add(1)
+		droppedByPathRule.Add(1)
+		return false
+	}
+	return true
+}
+
+func containsStatus(statusCodes []int, status int) bool {
+	for _, code := range statusCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// startSamplingReporter periodically logs how many entries were dropped. It writes
+// through the unsampled core so the report itself is never sampled away. A nil
+// core keeps the one from the previous call.
+func (l *Logger) startSamplingReporter(core zapcore.Core, config SamplingConfig) {
+	l.reporter.mu.Lock()
+	defer l.reporter.mu.Unlock()
+
+	if core != nil {
+		l.reporter.core = core
+	}
+	core = l.reporter.core
+
+	if l.reporter.stop != nil {
+		close(l.reporter.stop)
+		l.reporter.stop = nil
+	}
+	if !config.Enabled || config.ReportInterval <= 0 || core == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	l.reporter.stop = stop
+	reporter := zap.New(core).With(zap.String("logger_name", "sampling"))
+	last := l.SamplingDropped()
+
+	go func() {
+		ticker := time.NewTicker(config.ReportInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := l.SamplingDropped()
+				if current.Total() == last.Total() {
+					continue
+				}
+				reporter.Info("log entries dropped by sampling",
+					zap.Uint64("dropped", current.Total()-last.Total()),
+					zap.Uint64("dropped_sampler", current.Sampler-last.Sampler),
+					zap.Uint64("dropped_level_rate", current.LevelRate-last.LevelRate),
+					zap.Uint64("dropped_path_rule", current.PathRule-last.PathRule),
+					zap.String("interval", config.ReportInterval.String()),
+				)
+				last = current
+			}
+		}
+	}()
+}
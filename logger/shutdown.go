@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds Shutdown when its context has no deadline.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// Shutdown flushes and closes the default Logger. See Logger.Shutdown.
+func Shutdown(ctx context.Context) error {
+	return Default().Shutdown(ctx)
+}
+
+// Close is Shutdown with DefaultShutdownTimeout.
+func Close() error {
+	return Default().Close()
+}
+
+// ShutdownOnSignal installs ShutdownOnSignal for the default Logger.
+func ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) func() {
+	return Default().ShutdownOnSignal(timeout, signals...)
+}
+
+// Shutdown stops accepting entries, flushes every core, drains buffered output
+// and closes file writers. It returns when everything is flushed or ctx is done,
+// whichever comes first; without a deadline it waits at most
+// DefaultShutdownTimeout. Later calls wait for the first one to finish.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultShutdownTimeout)
+		defer cancel()
+	}
+
+	l.shutdownOnce.Do(func() {
+		l.shutdownDone = make(chan struct{})
+		go func() {
+			defer close(l.shutdownDone)
+			l.shutdownErr = l.shutdown()
+		}()
+	})
+
+	select {
+	case <-l.shutdownDone:
+		return l.shutdownErr
+	case <-ctx.Done():
+		return fmt.Errorf("logger shutdown: %w", ctx.Err())
+	}
+}
+
+func (l *Logger) Close() error {
+	return l.Shutdown(context.Background())
+}
+
+func (l *Logger) shutdown() error {
+	l.closed.Store(true)
+	return l.closeOutputs()
+}
+
+// release flushes and closes what l owns without marking it closed, for a Logger
+// that Init replaced: entries logged through it afterwards still reach stdout or
+// the writer given to New, and are dropped by the files and outputs it closed.
+// Like Shutdown, it waits at most until ctx is done.
+func (l *Logger) release(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- l.closeOutputs() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("logger release: %w", ctx.Err())
+	}
+}
+
+// closeOutputs runs once, for release or Shutdown, whichever comes first.
+func (l *Logger) closeOutputs() error {
+	l.releaseOnce.Do(func() {
+		l.stop()
+
+		errs := syncErrors(l.zap.Sync())
+		// Closers run last-registered first, so buffers drain before the files
+		// they write to are closed.
+		for i := len(l.closers) - 1; i >= 0; i-- {
+			if err := l.closers[i](); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		l.releaseErr = errors.Join(errs...)
+	})
+	return l.releaseErr
+}
+
+// syncErrors drops the errors terminals and pipes return for fsync, which mean
+// there is nothing to flush rather than that data was lost.
+func syncErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	all := []error{err}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		all = multi.Unwrap()
+	}
+
+	var errs []error
+	for _, err := range all {
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.ENOTSUP) {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ShutdownOnSignal flushes the Logger when one of signals (SIGINT and SIGTERM by
+// default) arrives, waiting at most timeout, then re-raises the signal with its
+// default handling so the process exits as it would have. Use it only when the
+// application does not handle these signals itself; otherwise call Shutdown from
+// that handler. Call the returned function to remove the hook.
+func (l *Logger) ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) func() {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		select {
+		case <-done:
+			return
+		case sig := <-received:
+			l.slog.InfoContext(context.Background(), "shutting down logger on signal", slog.String("signal", sig.String()))
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			err := l.Shutdown(ctx)
+			cancel()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logger shutdown: %v\n", err)
+			}
+
+			signal.Reset(signals...)
+			if process, err := os.FindProcess(os.Getpid()); err != nil || process.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(received)
+		close(done)
+		<-exited
+	}
+}
@@ -0,0 +1,347 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBatchSize     = 512
+	defaultBatchInterval = time.Second
+	defaultBatchQueue    = 8192
+	defaultExportTimeout = 10 * time.Second
+	defaultMaxRetries    = 3
+)
+
+// batchRecord is an entry waiting in a batchSink. encoded is a copy, as the
+// encoder reuses its buffer once Write returns.
+type batchRecord struct {
+	entry   zapcore.Entry
+	encoded []byte
+}
+
+// permanentError marks an export failure that retrying cannot fix, such as a
+// rejected payload.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// partialExportError reports an export that delivered part of its batch:
+// rejected entries are given up on and retry is sent again.
+type partialExportError struct {
+	err      error
+	rejected []batchRecord
+	retry    []batchRecord
+}
+
+func (e *partialExportError) Error() string { return e.err.Error() }
+func (e *partialExportError) Unwrap() error { return e.err }
+
+// batchExporter is what a batchSink delivers with. export sends one batch; close,
+// when set, runs after the last batch on Close; failed, when set, is told about
+// the entries given up on.
+type batchExporter struct {
+	export func(ctx context.Context, batch []batchRecord) error
+	close  func() error
+	failed func(records []batchRecord, err error)
+}
+
+// batchSink collects entries and hands them to export in batches of BatchSize
+// and BatchBytes, or every BatchInterval, from a background goroutine. Failed
+// exports are retried MaxRetries times with MinBackoff..MaxBackoff between
+// attempts; what still fails goes to the fallback file, when there is one. By
+// default Write never waits on the network: when QueueSize entries are pending
+// the newest is dropped.
+type batchSink struct {
+	exporter   batchExporter
+	fallback   Sink
+	size       int
+	maxBytes   int
+	queueSize  int
+	policy     OverflowPolicy
+	dropLevel  zapcore.Level
+	interval   time.Duration
+	timeout    time.Duration
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu           sync.Mutex
+	notFull      *sync.Cond
+	pending      []batchRecord
+	pendingBytes int
+	closed       bool
+
+	wake    chan struct{}
+	flushes chan chan error
+	stop    chan struct{}
+	exited  chan struct{}
+	once    sync.Once
+
+	dropped atomic.Uint64
+}
+
+// newBatchSink starts the exporting goroutine.
+func newBatchSink(output OutputConfig, exporter batchExporter) *batchSink {
+	s := &batchSink{
+		exporter:   exporter,
+		size:       output.BatchSize,
+		maxBytes:   output.BatchBytes,
+		queueSize:  output.QueueSize,
+		policy:     output.Overflow,
+		interval:   output.BatchInterval,
+		timeout:    output.Timeout,
+		retries:    output.MaxRetries,
+		minBackoff: output.MinBackoff,
+		maxBackoff: output.MaxBackoff,
+		wake:       make(chan struct{}, 1),
+		flushes:    make(chan chan error),
+		stop:       make(chan struct{}),
+		exited:     make(chan struct{}),
+	}
+	s.notFull = sync.NewCond(&s.mu)
+	if output.FallbackPath != "" {
+		s.fallback = NewWriterSink(newRotatingFile(Config{
+			FilePath:   output.FallbackPath,
+			FileSize:   output.FileSize,
+			MaxAge:     output.MaxAge,
+			MaxBackups: output.MaxBackups,
+		}))
+	}
+	if s.size <= 0 {
+		s.size = defaultBatchSize
+	}
+	if s.queueSize < s.size {
+		s.queueSize = defaultBatchQueue
+		if s.queueSize < s.size {
+			s.queueSize = s.size
+		}
+	}
+	if s.policy == "" {
+		s.policy = OverflowDropNewest
+	}
+	dropLevel := output.DropLevel
+	if dropLevel == "" {
+		dropLevel = defaultAsyncDropLevel
+	}
+	s.dropLevel = getZapLogLevel(dropLevel)
+	if s.interval <= 0 {
+		s.interval = defaultBatchInterval
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultExportTimeout
+	}
+	if s.retries == 0 {
+		s.retries = defaultMaxRetries
+	}
+	if s.minBackoff <= 0 {
+		s.minBackoff = defaultMinBackoff
+	}
+	if s.maxBackoff < s.minBackoff {
+		s.maxBackoff = defaultMaxBackoff
+		if s.maxBackoff < s.minBackoff {
+			s.maxBackoff = s.minBackoff
+		}
+	}
+	go s.run()
+	return s
+}
+
+func (s *batchSink) Write(entry zapcore.Entry, encoded []byte) error {
+	s.mu.Lock()
+	for !s.closed && len(s.pending) >= s.queueSize {
+		switch {
+		case s.policy == OverflowDropNewest,
+			s.policy == OverflowDropBelowLevel && entry.Level < s.dropLevel:
+			s.mu.Unlock()
+			s.dropped.Add(1)
+			return nil
+		case s.policy == OverflowDropOldest:
+			s.pendingBytes -= len(s.pending[0].encoded)
+			s.pending = s.pending[1:]
+			s.dropped.Add(1)
+		default:
+			s.signal()
+			s.notFull.Wait()
+		}
+	}
+	if s.closed {
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return nil
+	}
+	s.pending = append(s.pending, batchRecord{entry: entry, encoded: append([]byte(nil), encoded...)})
+	s.pendingBytes += len(encoded)
+	if len(s.pending) >= s.size || s.maxBytes > 0 && s.pendingBytes >= s.maxBytes {
+		s.signal()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *batchSink) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Sync exports everything pending and reports the batches that failed.
+func (s *batchSink) Sync() error {
+	result := make(chan error, 1)
+	select {
+	case s.flushes <- result:
+		return <-result
+	case <-s.exited:
+		return nil
+	}
+}
+
+// Close exports what is left and stops the goroutine.
+func (s *batchSink) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.exited
+
+	var errs []error
+	if s.exporter.close != nil {
+		errs = append(errs, s.exporter.close())
+	}
+	if s.fallback != nil {
+		errs = append(errs, s.fallback.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (s *batchSink) run() {
+	defer close(s.exited)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.mu.Lock()
+			s.closed = true
+			s.notFull.Broadcast()
+			s.mu.Unlock()
+			_ = s.flush()
+			return
+		case result := <-s.flushes:
+			result <- s.flush()
+		case <-s.wake:
+			_ = s.flush()
+		case <-ticker.C:
+			_ = s.flush()
+		}
+	}
+}
+
+func (s *batchSink) flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending, s.pendingBytes = nil, 0
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+
+	var errs []error
+	for len(pending) > 0 {
+		n := s.batchLength(pending)
+		if failed, err := s.exportWithRetry(pending[:n]); err != nil {
+			errs = append(errs, err)
+			errs = append(errs, s.giveUp(failed, err)...)
+		}
+		pending = pending[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// batchLength is how many of pending fit in the next batch; at least one, so an
+// oversized entry is still sent on its own.
+func (s *batchSink) batchLength(pending []batchRecord) int {
+	n := min(len(pending), s.size)
+	if s.maxBytes <= 0 {
+		return n
+	}
+	total := 0
+	for i, record := range pending[:n] {
+		total += len(record.encoded)
+		if total > s.maxBytes && i > 0 {
+			return i
+		}
+	}
+	return n
+}
+
+// exportWithRetry returns the entries that could not be delivered.
+func (s *batchSink) exportWithRetry(batch []batchRecord) ([]batchRecord, error) {
+	var failed []batchRecord
+	var rejectErr error
+	backoff := s.minBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err := s.exporter.export(ctx, batch)
+		cancel()
+		if err == nil {
+			return failed, rejectErr
+		}
+
+		var partial *partialExportError
+		var perm permanentError
+		switch {
+		case errors.As(err, &partial):
+			failed = append(failed, partial.rejected...)
+			if len(partial.rejected) > 0 {
+				rejectErr = err
+			}
+			batch = partial.retry
+			if len(batch) == 0 {
+				return failed, err
+			}
+		case errors.As(err, &perm):
+			return append(failed, batch...), err
+		}
+		if attempt >= s.retries {
+			return append(failed, batch...), err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			// Shutting down: one last attempt without waiting.
+			timer.Stop()
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// giveUp writes entries that could not be exported to the fallback file.
+func (s *batchSink) giveUp(failed []batchRecord, err error) []error {
+	if len(failed) > 0 && s.exporter.failed != nil {
+		s.exporter.failed(failed, err)
+	}
+	if s.fallback == nil {
+		s.dropped.Add(uint64(len(failed)))
+		return nil
+	}
+	var errs []error
+	for _, record := range failed {
+		if err := s.fallback.Write(record.entry, record.encoded); err != nil {
+			s.dropped.Add(1)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const elasticsearchDateFormat = "2006.01.02"
+
+// newElasticsearchSink indexes entries through the _bulk API into daily indices.
+// Address is the cluster URL. Documents the cluster rejects are given up on,
+// except those rejected for load (429), which are retried.
+func newElasticsearchSink(output OutputConfig) (Sink, error) {
+	index := output.Index
+	if index == "" {
+		index = output.ServiceName
+	}
+	if index == "" {
+		index = "logs"
+	}
+	index = strings.ToLower(index)
+
+	headers := map[string]string{}
+	for key, value := range output.Headers {
+		headers[key] = value
+	}
+	if output.APIKey != "" {
+		headers["Authorization"] = "ApiKey " + output.APIKey
+	}
+	endpoint := strings.TrimRight(output.Address, "/")
+	if !strings.HasSuffix(endpoint, "/_bulk") {
+		endpoint += "/_bulk"
+	}
+	client := &http.Client{}
+
+	export := func(ctx context.Context, batch []batchRecord) error {
+		body, err := elasticsearchBulkBody(index, batch)
+		if err != nil {
+			return permanent(err)
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return permanent(err)
+		}
+		request.Header.Set("Content-Type", "application/x-ndjson")
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+			err := fmt.Errorf("POST %s: %s: %s", endpoint, response.Status, bytes.TrimSpace(detail))
+			if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+				return err
+			}
+			return permanent(err)
+		}
+		return elasticsearchBulkResult(endpoint, response.Body, batch)
+	}
+	return newBatchSink(output, batchExporter{export: export}), nil
+}
+
+func elasticsearchBulkBody(index string, batch []batchRecord) ([]byte, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range batch {
+		action := map[string]map[string]string{
+			"create": {"_index": index + "-" + record.entry.Time.UTC().Format(elasticsearchDateFormat)},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(elasticsearchDocument(record)); err != nil {
+			return nil, err
+		}
+	}
+	return body.Bytes(), nil
+}
+
+// elasticsearchDocument is the entry's fields with @timestamp and message in
+// place of ts and msg, as Kibana expects.
+func elasticsearchDocument(record batchRecord) map[string]any {
+	fields, ok := decodeEntryFields(record.encoded)
+	if !ok {
+		fields = map[string]any{
+			"message": strings.TrimRight(string(record.encoded), "\n"),
+			"level":   record.entry.Level.String(),
+		}
+	} else {
+		delete(fields, "msg")
+		delete(fields, "ts")
+		fields["message"] = record.entry.Message
+	}
+	fields["@timestamp"] = record.entry.Time.UTC().Format(time.RFC3339Nano)
+	return fields
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// elasticsearchBulkResult turns per-document failures into a partial export:
+// documents rejected with 429 are retried, the rest are given up on.
+func elasticsearchBulkResult(endpoint string, body io.Reader, batch []batchRecord) error {
+	var result elasticsearchBulkResponse
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return permanent(fmt.Errorf("POST %s: decode response: %w", endpoint, err))
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	partial := &partialExportError{}
+	var reason string
+	for i, item := range result.Items {
+		if i >= len(batch) {
+			break
+		}
+		for _, outcome := range item {
+			switch {
+			case outcome.Status >= 200 && outcome.Status < 300:
+			case outcome.Status == http.StatusTooManyRequests:
+				partial.retry = append(partial.retry, batch[i])
+			default:
+				partial.rejected = append(partial.rejected, batch[i])
+				if reason == "" {
+					reason = outcome.Error.Type + ": " + outcome.Error.Reason
+				}
+			}
+		}
+	}
+	if len(partial.retry) == 0 && len(partial.rejected) == 0 {
+		return nil
+	}
+	partial.err = fmt.Errorf("POST %s: %d documents rejected, %d to retry: %s", endpoint, len(partial.rejected), len(partial.retry), reason)
+	return partial
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var validCompressions = []string{"none", "gzip"}
+
+// httpExporter posts batches to an intake endpoint for the exporting sinks.
+type httpExporter struct {
+	client      *http.Client
+	endpoint    string
+	contentType string
+	headers     map[string]string
+	gzip        bool
+	// retryable reports whether a failed status is worth retrying; connection
+	// errors always are.
+	retryable func(code int) bool
+}
+
+func (e *httpExporter) post(ctx context.Context, body []byte) error {
+	if e.gzip {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return permanent(err)
+		}
+		if err := writer.Close(); err != nil {
+			return permanent(err)
+		}
+		body = compressed.Bytes()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return permanent(err)
+	}
+	request.Header.Set("Content-Type", e.contentType)
+	if e.gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range e.headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("POST %s: %s: %s", e.endpoint, response.Status, bytes.TrimSpace(detail))
+	if e.retryable(response.StatusCode) {
+		return err
+	}
+	return permanent(err)
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KafkaMessage is one entry on its way to Kafka. Value is the JSON entry; Key is
+// its trace_id, so the logs of one request land in one partition, or nil when
+// the entry has none.
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+	Time    time.Time
+}
+
+// KafkaProducer is the client the kafka sink sends through, so any Kafka library
+// can be plugged in. Produce returns once the messages are delivered or have
+// failed; the result is nil when all were delivered, otherwise it has one
+// entry per message, nil for those delivered.
+type KafkaProducer interface {
+	Produce(ctx context.Context, messages []KafkaMessage) []error
+	Close() error
+}
+
+// KafkaDeliveryErrorFunc is told about each message the sink gives up on after
+// its retries.
+type KafkaDeliveryErrorFunc func(message KafkaMessage, err error)
+
+// NewKafkaSink sends entries to output.Topic through producer, batched and
+// retried like the other exporting outputs, and closes producer on Close. It is
+// meant for a SinkFactory:
+//
+//	logger.RegisterSink("kafka", func(output logger.OutputConfig) (logger.Sink, error) {
+//		producer, err := newProducer(strings.Split(output.Address, ","))
+//		if err != nil {
+//			return nil, err
+//		}
+//		return logger.NewKafkaSink(producer, output, onDeliveryError)
+//	})
+func NewKafkaSink(producer KafkaProducer, output OutputConfig, onDeliveryError KafkaDeliveryErrorFunc) (Sink, error) {
+	if producer == nil {
+		return nil, fmt.Errorf("kafka output needs a producer")
+	}
+	if output.Topic == "" {
+		return nil, fmt.Errorf("kafka output needs a topic")
+	}
+
+	export := func(ctx context.Context, batch []batchRecord) error {
+		messages := make([]KafkaMessage, len(batch))
+		for i, record := range batch {
+			messages[i] = kafkaMessage(output, record)
+		}
+		results := producer.Produce(ctx, messages)
+
+		partial := &partialExportError{}
+		var errs []error
+		for i, err := range results {
+			if err != nil && i < len(batch) {
+				partial.retry = append(partial.retry, batch[i])
+				errs = append(errs, err)
+			}
+		}
+		if len(partial.retry) == 0 {
+			return nil
+		}
+		partial.err = fmt.Errorf("kafka: %d of %d messages to %s failed: %w", len(partial.retry), len(batch), output.Topic, errors.Join(errs...))
+		return partial
+	}
+
+	var failed func(records []batchRecord, err error)
+	if onDeliveryError != nil {
+		failed = func(records []batchRecord, err error) {
+			for _, record := range records {
+				onDeliveryError(kafkaMessage(output, record), err)
+			}
+		}
+	}
+	return newBatchSink(output, batchExporter{export: export, close: producer.Close, failed: failed}), nil
+}
+
+func kafkaMessage(output OutputConfig, record batchRecord) KafkaMessage {
+	message := KafkaMessage{
+		Topic: output.Topic,
+		Value: []byte(strings.TrimRight(string(record.encoded), "\n")),
+		Time:  record.entry.Time,
+		Headers: map[string]string{
+			"level":   record.entry.Level.String(),
+			"service": output.ServiceName,
+		},
+	}
+	if fields, ok := decodeEntryFields(record.encoded); ok {
+		if traceID := kafkaTraceID(fields); traceID != "" {
+			message.Key = []byte(traceID)
+		}
+	}
+	return message
+}
+
+// kafkaTraceID reads the trace_id AddDDFields writes, at the top level or in the
+// dd group.
+func kafkaTraceID(fields map[string]any) string {
+	if traceID, ok := fields["trace_id"].(string); ok && traceID != "" {
+		return traceID
+	}
+	if dd, ok := fields["dd"].(map[string]any); ok {
+		if traceID, ok := dd["trace_id"].(string); ok {
+			return traceID
+		}
+	}
+	return ""
+}
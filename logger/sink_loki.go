@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// newLokiSink pushes entries to Loki's push API. Each entry is a line of the
+// stream labelled with the service, env, level and logger_name, plus Labels;
+// Address is the Loki base URL or the full push URL. Tenants and credentials go
+// in Headers, e.g. X-Scope-OrgID.
+func newLokiSink(output OutputConfig) (Sink, error) {
+	exporter := &httpExporter{
+		client:      &http.Client{},
+		endpoint:    lokiPushEndpoint(output.Address),
+		contentType: "application/json",
+		headers:     output.Headers,
+		gzip:        output.Compression == "gzip",
+		retryable:   lokiRetryableStatus,
+	}
+
+	export := func(ctx context.Context, batch []batchRecord) error {
+		body, err := json.Marshal(lokiPush(output, batch))
+		if err != nil {
+			return permanent(err)
+		}
+		return exporter.post(ctx, body)
+	}
+	return newBatchSink(output, batchExporter{export: export}), nil
+}
+
+func lokiPushEndpoint(address string) string {
+	address = strings.TrimRight(address, "/")
+	if strings.HasSuffix(address, "/loki/api/v1/push") {
+		return address
+	}
+	return address + "/loki/api/v1/push"
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// lokiPush groups a batch into one stream per label set, keeping the order of
+// entries within each stream.
+func lokiPush(output OutputConfig, batch []batchRecord) lokiPushRequest {
+	var request lokiPushRequest
+	streams := map[string]*lokiStream{}
+	for _, record := range batch {
+		labels := lokiLabels(output, record)
+		key := lokiStreamKey(labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			request.Streams = append(request.Streams, stream)
+		}
+		line := strings.TrimRight(string(record.encoded), "\n")
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(record.entry.Time.UnixNano(), 10), line})
+	}
+	return request
+}
+
+func lokiLabels(output OutputConfig, record batchRecord) map[string]string {
+	labels := map[string]string{}
+	for key, value := range output.Labels {
+		labels[key] = value
+	}
+	labels["service"] = output.ServiceName
+	labels["env"] = output.Env
+	labels["level"] = record.entry.Level.String()
+
+	name := record.entry.LoggerName
+	if fields, ok := decodeEntryFields(record.encoded); ok {
+		if fieldName, ok := fields["logger_name"].(string); ok {
+			name = fieldName
+		}
+	}
+	if name != "" {
+		labels["logger_name"] = name
+	}
+	for key, value := range labels {
+		if value == "" {
+			delete(labels, key)
+		}
+	}
+	return labels
+}
+
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var key strings.Builder
+	for _, name := range keys {
+		key.WriteString(name)
+		key.WriteByte('=')
+		key.WriteString(strconv.Quote(labels[name]))
+		key.WriteByte(',')
+	}
+	return key.String()
+}
+
+// lokiRetryableStatus retries rate limits and server errors; Loki answers 400
+// to entries it will never accept, such as ones too old.
+func lokiRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+)
+
+// netSink writes each entry to a tcp, udp or unix socket. Dialing happens in the
+// background, so a missing or unreachable collector never blocks the caller: the
+// entry that starts a dial is sent once it connects, and entries logged while
+// it is in progress, or during the exponential backoff after it fails, are
+// dropped.
+type netSink struct {
+	network     string
+	address     string
+	dialTimeout time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	// frame turns an encoded entry into the bytes sent; nil sends it as is.
+	frame func(entry zapcore.Entry, encoded []byte) []byte
+
+	mu       sync.Mutex
+	conn     net.Conn
+	dialing  bool
+	backoff  time.Duration
+	nextDial time.Time
+	// closed drops later writes instead of dialing again.
+	closed bool
+}
+
+func newNetSink(output OutputConfig) (Sink, error) {
+	return newNetSinkFor(output.Type, output)
+}
+
+func newNetSinkFor(network string, output OutputConfig) (*netSink, error) {
+	if output.Address == "" {
+		return nil, fmt.Errorf("%s output needs an address", output.Type)
+	}
+	s := &netSink{
+		network:     network,
+		address:     output.Address,
+		dialTimeout: output.DialTimeout,
+		minBackoff:  output.MinBackoff,
+		maxBackoff:  output.MaxBackoff,
+	}
+	if s.dialTimeout <= 0 {
+		s.dialTimeout = defaultDialTimeout
+	}
+	if s.minBackoff <= 0 {
+		s.minBackoff = defaultMinBackoff
+	}
+	if s.maxBackoff < s.minBackoff {
+		s.maxBackoff = defaultMaxBackoff
+		if s.maxBackoff < s.minBackoff {
+			s.maxBackoff = s.minBackoff
+		}
+	}
+	return s, nil
+}
+
+func (s *netSink) Write(entry zapcore.Entry, encoded []byte) error {
+	payload := encoded
+	if s.frame != nil {
+		payload = s.frame(entry, encoded)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.dialing {
+		return nil
+	}
+
+	if s.conn != nil {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.dialTimeout))
+		if _, err := s.conn.Write(payload); err == nil {
+			return nil
+		}
+		// The peer has most likely just restarted, so it is redialed right away.
+		_ = s.conn.Close()
+		s.conn = nil
+	} else if time.Now().Before(s.nextDial) {
+		return nil
+	}
+
+	// The caller may reuse encoded once Write returns.
+	s.dialing = true
+	go s.dial(append([]byte(nil), payload...))
+	return nil
+}
+
+// dial connects outside the lock and sends the entry that asked for it.
+func (s *netSink) dial(payload []byte) {
+	conn, err := net.DialTimeout(s.network, s.address, s.dialTimeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dialing = false
+	if err != nil {
+		s.scheduleRedial()
+		return
+	}
+	if s.closed {
+		_ = conn.Close()
+		return
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(s.dialTimeout))
+	if _, err := conn.Write(payload); err != nil {
+		_ = conn.Close()
+		s.scheduleRedial()
+		return
+	}
+	s.conn = conn
+	s.backoff = 0
+}
+
+func (s *netSink) scheduleRedial() {
+	if s.backoff == 0 {
+		s.backoff = s.minBackoff
+	} else {
+		s.backoff *= 2
+	}
+	if s.backoff > s.maxBackoff {
+		s.backoff = s.maxBackoff
+	}
+	s.nextDial = time.Now().Add(s.backoff)
+}
+
+func (s *netSink) Sync() error {
+	return nil
+}
+
+func (s *netSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const otlpScopeName = "github.com/pawatthir/blogger"
+
+var otlpProtocols = []string{"grpc", "http"}
+
+// newOTLPSink exports entries as OTLP log records over gRPC or HTTP/protobuf.
+// The JSON fields of each entry become attributes, and the trace_id and span_id
+// added from the record context become the record's trace correlation.
+func newOTLPSink(output OutputConfig) (Sink, error) {
+	if output.Address == "" {
+		return nil, fmt.Errorf("otlp output needs an address")
+	}
+	resource := otlpResource(output)
+
+	var send func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error
+	var closeFn func() error
+	switch output.Protocol {
+	case "", "grpc":
+		creds := credentials.NewTLS(&tls.Config{})
+		if output.Insecure {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(output.Address, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("otlp grpc client: %w", err)
+		}
+		client := collogspb.NewLogsServiceClient(conn)
+		headers := metadata.New(output.Headers)
+		send = func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+			_, err := client.Export(metadata.NewOutgoingContext(ctx, headers), request)
+			return otlpGRPCError(err)
+		}
+		closeFn = conn.Close
+	case "http":
+		exporter := &httpExporter{
+			client:      &http.Client{},
+			endpoint:    otlpHTTPEndpoint(output.Address, output.Insecure),
+			contentType: "application/x-protobuf",
+			headers:     output.Headers,
+			gzip:        output.Compression == "gzip",
+			retryable:   otlpRetryableStatus,
+		}
+		send = func(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+			body, err := proto.Marshal(request)
+			if err != nil {
+				return permanent(err)
+			}
+			return exporter.post(ctx, body)
+		}
+	default:
+		return nil, fmt.Errorf("unknown otlp protocol %q", output.Protocol)
+	}
+
+	export := func(ctx context.Context, batch []batchRecord) error {
+		records := make([]*logspb.LogRecord, len(batch))
+		for i, record := range batch {
+			records[i] = otlpLogRecord(record)
+		}
+		return send(ctx, &collogspb.ExportLogsServiceRequest{
+			ResourceLogs: []*logspb.ResourceLogs{{
+				Resource: resource,
+				ScopeLogs: []*logspb.ScopeLogs{{
+					Scope:      &commonpb.InstrumentationScope{Name: otlpScopeName},
+					LogRecords: records,
+				}},
+			}},
+		})
+	}
+	return newBatchSink(output, batchExporter{export: export, close: closeFn}), nil
+}
+
+func otlpResource(output OutputConfig) *resourcepb.Resource {
+	attributes := map[string]string{
+		"service.name":           output.ServiceName,
+		"deployment.environment": output.Env,
+		"service.version":        output.Version,
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attributes["host.name"] = hostname
+	}
+	for key, value := range output.ResourceAttributes {
+		attributes[key] = value
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for key, value := range attributes {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	resource := &resourcepb.Resource{}
+	for _, key := range keys {
+		resource.Attributes = append(resource.Attributes, otlpKeyValue(key, attributes[key]))
+	}
+	return resource
+}
+
+// otlpHTTPEndpoint accepts a bare host:port or a URL without the signal path.
+func otlpHTTPEndpoint(address string, insecure bool) string {
+	if !strings.Contains(address, "://") {
+		scheme := "https://"
+		if insecure {
+			scheme = "http://"
+		}
+		address = scheme + address
+	}
+	if rest := address[strings.Index(address, "://")+3:]; !strings.Contains(rest, "/") {
+		address += "/v1/logs"
+	}
+	return address
+}
+
+// otlpEntryKeys are the JSON fields that map to LogRecord fields rather than
+// attributes. dd repeats the resource and the trace correlation.
+var otlpEntryKeys = map[string]bool{"ts": true, "level": true, "msg": true, "trace_id": true, "span_id": true, "dd": true}
+
+func otlpLogRecord(record batchRecord) *logspb.LogRecord {
+	entry := record.entry
+	logRecord := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       otlpSeverity(entry.Level),
+		SeverityText:         entry.Level.CapitalString(),
+		Body:                 otlpValue(entry.Message),
+	}
+
+	fields, ok := decodeEntryFields(record.encoded)
+	if !ok {
+		// Not JSON, e.g. the console encoder: the line is the body.
+		logRecord.Body = otlpValue(strings.TrimRight(string(record.encoded), "\n"))
+		return logRecord
+	}
+	logRecord.TraceId = hexID(fields["trace_id"], 16)
+	logRecord.SpanId = hexID(fields["span_id"], 8)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if !otlpEntryKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		logRecord.Attributes = append(logRecord.Attributes, otlpKeyValue(key, fields[key]))
+	}
+	return logRecord
+}
+
+// decodeEntryFields parses an entry written by the JSON encoder.
+func decodeEntryFields(encoded []byte) (map[string]any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+func hexID(value any, size int) []byte {
+	text, _ := value.(string)
+	id, err := hex.DecodeString(text)
+	if err != nil || len(id) != size {
+		return nil
+	}
+	return id
+}
+
+func otlpSeverity(level zapcore.Level) logspb.SeverityNumber {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	case level == zapcore.InfoLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	case level == zapcore.WarnLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	case level == zapcore.ErrorLevel:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL
+	}
+}
+
+func otlpKeyValue(key string, value any) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: otlpValue(value)}
+}
+
+func otlpValue(value any) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+		}
+		f, _ := v.Float64()
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}}
+	case []any:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i, item := range v {
+			values[i] = otlpValue(item)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		list := &commonpb.KeyValueList{}
+		for _, key := range keys {
+			list.Values = append(list.Values, otlpKeyValue(key, v[key]))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: list}}
+	case nil:
+		return &commonpb.AnyValue{}
+	default:
+		return otlpValue(fmt.Sprint(v))
+	}
+}
+
+// otlpGRPCError marks the status codes the OTLP specification does not retry as
+// permanent.
+func otlpGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return err
+	default:
+		return permanent(err)
+	}
+}
+
+// otlpRetryableStatus lists the HTTP statuses the OTLP specification retries.
+func otlpRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
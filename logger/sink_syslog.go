@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogNetworks = []string{"udp", "tcp", "unix", "unixgram"}
+
+// syslogSeverity maps zap levels to RFC 5424 severities.
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 7
+	case level == zapcore.InfoLevel:
+		return 6
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// newSyslogSink sends RFC 5424 messages whose MSG is the encoded entry. Stream
+// transports (tcp, unix) use the octet-counting framing of RFC 6587; udp and
+// unixgram send one message per datagram.
+func newSyslogSink(output OutputConfig) (Sink, error) {
+	network := output.Network
+	if network == "" {
+		network = "udp"
+	}
+	if !containsString(syslogNetworks, network) {
+		return nil, fmt.Errorf("unknown syslog network %q", network)
+	}
+
+	facilityName := output.Facility
+	if facilityName == "" {
+		facilityName = "user"
+	}
+	facility, ok := syslogFacilities[facilityName]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facilityName)
+	}
+
+	sink, err := newNetSinkFor(network, output)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	header := syslogHeaderField(hostname, 255) + " " + syslogHeaderField(output.AppName, 48) + " " + strconv.Itoa(os.Getpid()) + " - - "
+	stream := network == "tcp" || network == "unix"
+
+	sink.frame = func(entry zapcore.Entry, encoded []byte) []byte {
+		var msg bytes.Buffer
+		fmt.Fprintf(&msg, "<%d>1 %s %s", facility*8+syslogSeverity(entry.Level), entry.Time.UTC().Format(time.RFC3339Nano), header)
+		msg.Write(bytes.TrimRight(encoded, "\n"))
+		if !stream {
+			return msg.Bytes()
+		}
+		return append([]byte(strconv.Itoa(msg.Len())+" "), msg.Bytes()...)
+	}
+	return sink, nil
+}
+
+// syslogHeaderField keeps a header field within the printable ASCII RFC 5424
+// allows, using "-" for an empty value.
+func syslogHeaderField(value string, maxLength int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	return value
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
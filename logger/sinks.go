@@ -0,0 +1,326 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OutputConfig is one named destination. When Config.Outputs is set it replaces
+// the stdout and file outputs chosen by UseJSON and FileEnabled.
+type OutputConfig struct {
+	Name string `yaml:"name" json:"name" mapstructure:"name"`
+	// Type selects the sink: stdout, stderr, file, tcp, udp, unix, syslog or a type
+	// added with RegisterSink.
+	Type string `yaml:"type" json:"type" mapstructure:"type"`
+	// Encoder is json (the default) or console.
+	Encoder string `yaml:"encoder" json:"encoder" mapstructure:"encoder"`
+	// Level is the lowest level written to this output; empty writes everything
+	// the logger accepts.
+	Level string `yaml:"level" json:"level" mapstructure:"level"`
+
+	// Path and the rotation settings configure the file type.
+	Path       string `yaml:"path" json:"path,omitempty" mapstructure:"path"`
+	FileSize   int    `yaml:"fileSize" json:"fileSize,omitempty" mapstructure:"fileSize"`
+	MaxAge     int    `yaml:"maxAge" json:"maxAge,omitempty" mapstructure:"maxAge"`
+	MaxBackups int    `yaml:"maxBackups" json:"maxBackups,omitempty" mapstructure:"maxBackups"`
+
+	// Address is host:port for tcp and udp or a socket path for unix. Syslog also
+	// uses Network, one of udp (the default), tcp, unix or unixgram.
+	Network string `yaml:"network" json:"network,omitempty" mapstructure:"network"`
+	Address string `yaml:"address" json:"address,omitempty" mapstructure:"address"`
+	// DialTimeout and the backoff bounds tune reconnecting after a failed dial or
+	// write; entries logged while disconnected are dropped.
+	DialTimeout time.Duration `yaml:"dialTimeout" json:"dialTimeout,omitempty" mapstructure:"dialTimeout"`
+	MinBackoff  time.Duration `yaml:"minBackoff" json:"minBackoff,omitempty" mapstructure:"minBackoff"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff" json:"maxBackoff,omitempty" mapstructure:"maxBackoff"`
+
+	// Facility and AppName fill the syslog header; AppName defaults to the
+	// service name.
+	Facility string `yaml:"facility" json:"facility,omitempty" mapstructure:"facility"`
+	AppName  string `yaml:"appName" json:"appName,omitempty" mapstructure:"appName"`
+
+	// Protocol is grpc (the default) or http for otlp outputs, whose Address is
+	// host:port for grpc and a URL for http. Insecure disables TLS where the
+	// address does not say otherwise.
+	Protocol string            `yaml:"protocol" json:"protocol,omitempty" mapstructure:"protocol"`
+	Insecure bool              `yaml:"insecure" json:"insecure,omitempty" mapstructure:"insecure"`
+	Headers  map[string]string `yaml:"headers" json:"headers,omitempty" mapstructure:"headers"`
+	// Compression is gzip or none. Datadog outputs compress unless it is none;
+	// the others only when it is gzip.
+	Compression string `yaml:"compression" json:"compression,omitempty" mapstructure:"compression"`
+	// APIKey authenticates datadog outputs, falling back to DD_API_KEY, and
+	// elasticsearch outputs.
+	APIKey string `yaml:"apiKey" json:"-" mapstructure:"apiKey"`
+	// Source and Tags become ddsource (go when empty) and the ddtags added to
+	// env and version on datadog outputs.
+	Source string   `yaml:"source" json:"source,omitempty" mapstructure:"source"`
+	Tags   []string `yaml:"tags" json:"tags,omitempty" mapstructure:"tags"`
+	// Labels are added to the service, env, level and logger_name labels of loki
+	// streams.
+	Labels map[string]string `yaml:"labels" json:"labels,omitempty" mapstructure:"labels"`
+	// Index is the prefix of the daily elasticsearch indices, named
+	// <index>-YYYY.MM.DD after the entry's UTC date; the service name by default.
+	Index string `yaml:"index" json:"index,omitempty" mapstructure:"index"`
+	// Topic is where kafka sinks built with NewKafkaSink send entries.
+	Topic string `yaml:"topic" json:"topic,omitempty" mapstructure:"topic"`
+	// ResourceAttributes are added to the service.name, deployment.environment
+	// and service.version that exporters report.
+	ResourceAttributes map[string]string `yaml:"resourceAttributes" json:"resourceAttributes,omitempty" mapstructure:"resourceAttributes"`
+
+	// Exporters send BatchSize entries at a time, at most BatchBytes of encoded
+	// entries when set, or whatever is pending every BatchInterval. Once
+	// QueueSize entries are waiting, Overflow decides what happens to the next
+	// one, as for async outputs, except that it defaults to drop-newest. A
+	// failed export is retried MaxRetries times, using the backoff bounds above;
+	// a negative MaxRetries disables retries. Timeout bounds each attempt.
+	BatchSize     int            `yaml:"batchSize" json:"batchSize,omitempty" mapstructure:"batchSize"`
+	BatchBytes    int            `yaml:"batchBytes" json:"batchBytes,omitempty" mapstructure:"batchBytes"`
+	BatchInterval time.Duration  `yaml:"batchInterval" json:"batchInterval,omitempty" mapstructure:"batchInterval"`
+	QueueSize     int            `yaml:"queueSize" json:"queueSize,omitempty" mapstructure:"queueSize"`
+	Overflow      OverflowPolicy `yaml:"overflow" json:"overflow,omitempty" mapstructure:"overflow"`
+	DropLevel     string         `yaml:"dropLevel" json:"dropLevel,omitempty" mapstructure:"dropLevel"`
+	MaxRetries    int            `yaml:"maxRetries" json:"maxRetries,omitempty" mapstructure:"maxRetries"`
+	Timeout       time.Duration  `yaml:"timeout" json:"timeout,omitempty" mapstructure:"timeout"`
+	// FallbackPath is a file that entries an exporter gives up on are appended
+	// to, as encoded, instead of being lost.
+	FallbackPath string `yaml:"fallbackPath" json:"fallbackPath,omitempty" mapstructure:"fallbackPath"`
+
+	// Options holds the settings of sinks added with RegisterSink.
+	Options map[string]string `yaml:"options" json:"options,omitempty" mapstructure:"options"`
+
+	// ServiceName, Env and Version are filled in from the Logger, for sinks that
+	// tag what they send.
+	ServiceName string `yaml:"-" json:"-" mapstructure:"-"`
+	Env         string `yaml:"-" json:"-" mapstructure:"-"`
+	Version     string `yaml:"-" json:"-" mapstructure:"-"`
+}
+
+// Sink receives the encoded entries of one output. entry carries the level, time
+// and message for sinks that need them, such as syslog.
+type Sink interface {
+	Write(entry zapcore.Entry, encoded []byte) error
+	Sync() error
+	Close() error
+}
+
+// SinkFactory builds the sink of an output whose Type it was registered for.
+type SinkFactory func(output OutputConfig) (Sink, error)
+
+var (
+	sinkFactoriesMu sync.RWMutex
+	sinkFactories   = map[string]SinkFactory{}
+)
+
+func init() {
+	sinkFactories["stdout"] = func(OutputConfig) (Sink, error) {
+		return NewWriterSink(os.Stdout), nil
+	}
+	sinkFactories["stderr"] = func(OutputConfig) (Sink, error) {
+		return NewWriterSink(os.Stderr), nil
+	}
+	sinkFactories["file"] = newFileSink
+	sinkFactories["tcp"] = newNetSink
+	sinkFactories["udp"] = newNetSink
+	sinkFactories["unix"] = newNetSink
+	sinkFactories["syslog"] = newSyslogSink
+	sinkFactories["otlp"] = newOTLPSink
+	sinkFactories["datadog"] = newDatadogSink
+	sinkFactories["loki"] = newLokiSink
+	sinkFactories["elasticsearch"] = newElasticsearchSink
+}
+
+// RegisterSink adds an output type. It fails if the type is already registered.
+func RegisterSink(sinkType string, factory SinkFactory) error {
+	sinkFactoriesMu.Lock()
+	defer sinkFactoriesMu.Unlock()
+
+	if _, exists := sinkFactories[sinkType]; exists {
+		return fmt.Errorf("sink type %q is already registered", sinkType)
+	}
+	sinkFactories[sinkType] = factory
+	return nil
+}
+
+// SinkTypes lists the registered output types.
+func SinkTypes() []string {
+	sinkFactoriesMu.RLock()
+	defer sinkFactoriesMu.RUnlock()
+
+	types := make([]string, 0, len(sinkFactories))
+	for sinkType := range sinkFactories {
+		types = append(types, sinkType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func lookupSink(sinkType string) (SinkFactory, bool) {
+	sinkFactoriesMu.RLock()
+	defer sinkFactoriesMu.RUnlock()
+
+	factory, ok := sinkFactories[sinkType]
+	return factory, ok
+}
+
+// writerSink adapts an io.Writer; Sync and Close are forwarded when the writer
+// supports them.
+type writerSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewWriterSink wraps w for use by a SinkFactory. Writes are serialized.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{out: w}
+}
+
+func (s *writerSink) Write(_ zapcore.Entry, encoded []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.out.Write(encoded)
+	return err
+}
+
+func (s *writerSink) Sync() error {
+	if syncer, ok := s.out.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close leaves stdout and stderr open.
+func (s *writerSink) Close() error {
+	if s.out == os.Stdout || s.out == os.Stderr {
+		return nil
+	}
+	if closer, ok := s.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func newFileSink(output OutputConfig) (Sink, error) {
+	if output.Path == "" {
+		return nil, fmt.Errorf("file output needs a path")
+	}
+	return NewWriterSink(newRotatingFile(Config{
+		FilePath:   output.Path,
+		FileSize:   output.FileSize,
+		MaxAge:     output.MaxAge,
+		MaxBackups: output.MaxBackups,
+	})), nil
+}
+
+// sinkCore is zapcore.NewCore for a Sink.
+type sinkCore struct {
+	zapcore.LevelEnabler
+	enc  zapcore.Encoder
+	sink Sink
+}
+
+func newSinkCore(enc zapcore.Encoder, sink Sink, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &sinkCore{LevelEnabler: enabler, enc: enc, sink: sink}
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(clone)
+	}
+	return &sinkCore{LevelEnabler: c.LevelEnabler, enc: clone, sink: c.sink}
+}
+
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write checks the level again: levelCore hands entries to the whole tee of
+// outputs, so per-output levels are not applied by Check alone.
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(entry.Level) {
+		return nil
+	}
+	data, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	err = c.sink.Write(entry, data.Bytes())
+	data.Free()
+	if err != nil {
+		return err
+	}
+	if entry.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.sink.Sync()
+}
+
+var validEncoders = []string{"json", "console"}
+
+func newEncoder(name string) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	if name == "console" {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// newOutputCores builds a core per entry of config.Outputs. Outputs that fail to
+// build are skipped and reported in the returned error.
+func (l *Logger) newOutputCores(config Config, stdout Sink) ([]zapcore.Core, error) {
+	var cores []zapcore.Core
+	var errs []error
+	for i, output := range config.Outputs {
+		name := output.Name
+		if name == "" {
+			name = fmt.Sprintf("outputs[%d]", i)
+		}
+		if output.AppName == "" {
+			output.AppName = l.serviceName
+		}
+		output.ServiceName, output.Env, output.Version = l.serviceName, l.env, l.version
+
+		// A writer given to New replaces stdout and stays open on Shutdown.
+		var sink Sink
+		if output.Type == "stdout" && stdout != nil {
+			sink = stdout
+		} else {
+			factory, ok := lookupSink(output.Type)
+			if !ok {
+				errs = append(errs, fmt.Errorf("output %s: unknown type %q", name, output.Type))
+				continue
+			}
+			built, err := factory(output)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("output %s: %w", name, err))
+				continue
+			}
+			sink = built
+			l.closers = append(l.closers, sink.Close)
+		}
+
+		level := zapcore.DebugLevel
+		if output.Level != "" {
+			level = getZapLogLevel(output.Level)
+		}
+		cores = append(cores, l.newOutputCore(config, newEncoder(output.Encoder), sink, level))
+	}
+	return cores, errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// Propagator names accepted in TracingConfig.Propagators.
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+)
+
+const tracerName = "github.com/pawatthir/blogger"
+
+// TracingConfig makes the middlewares continue the caller's trace and start a
+// span per request and per outgoing call, so trace_id and span_id are logged
+// without the application wiring OpenTelemetry into each of them.
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
+	// Propagators are the header formats read from requests and written to
+	// outgoing calls: tracecontext (W3C traceparent and tracestate), baggage,
+	// b3 (the single b3 header) and b3multi (the X-B3-* headers); tracecontext
+	// and baggage when empty. Either B3 name reads both B3 forms.
+	Propagators []string `yaml:"propagators" json:"propagators,omitempty" mapstructure:"propagators"`
+}
+
+func validPropagator(name string) bool {
+	switch strings.ToLower(name) {
+	case PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi:
+		return true
+	}
+	return false
+}
+
+// WithTracerProvider sets the provider that starts the middlewares' spans when
+// Config.Tracing is enabled. Without it the global provider is used, as set by
+// otel.SetTracerProvider at the time each span starts.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
+
+// tracingState is what a Logger with tracing enabled starts spans with.
+type tracingState struct {
+	// provider is nil for the global provider.
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+func newTracingState(config TracingConfig, provider trace.TracerProvider) *tracingState {
+	if !config.Enabled {
+		return nil
+	}
+	names := config.Propagators
+	if len(names) == 0 {
+		names = []string{PropagatorTraceContext, PropagatorBaggage}
+	}
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, B3Propagator{})
+		case PropagatorB3Multi:
+			propagators = append(propagators, B3Propagator{MultipleHeaders: true})
+		}
+	}
+	return &tracingState{provider: provider, propagator: propagation.NewCompositeTextMapPropagator(propagators...)}
+}
+
+func (s *tracingState) tracer() trace.Tracer {
+	provider := s.provider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// TracingEnabled reports whether Config.Tracing was enabled when l was built.
+func (l *Logger) TracingEnabled() bool {
+	return l.tracing != nil
+}
+
+// StartServerSpan continues the trace carrier holds, read with the configured
+// propagators, in a new server span. With tracing disabled it returns ctx as it
+// is and a span that records nothing, so callers can end it unconditionally.
+func (l *Logger) StartServerSpan(ctx context.Context, name string, carrier propagation.TextMapCarrier, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if l.tracing == nil {
+		return ctx, nonRecordingSpan()
+	}
+	ctx = l.tracing.propagator.Extract(ctx, carrier)
+	return l.tracing.tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// StartClientSpan starts a client span for an outgoing call and writes it to
+// carrier with the configured propagators, so the callee continues the trace.
+// With tracing disabled it returns ctx as it is and a span that records nothing.
+func (l *Logger) StartClientSpan(ctx context.Context, name string, carrier propagation.TextMapCarrier, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if l.tracing == nil {
+		return ctx, nonRecordingSpan()
+	}
+	ctx, span := l.tracing.tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	l.tracing.propagator.Inject(ctx, carrier)
+	return ctx, span
+}
+
+// nonRecordingSpan is the no-op span of a context without one; ending it has no
+// effect, unlike ending the span an application may have put on ctx.
+func nonRecordingSpan() trace.Span {
+	return trace.SpanFromContext(context.Background())
+}
+
+// MetadataCarrier exposes gRPC metadata to the trace propagators, for
+// StartServerSpan and StartClientSpan.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	b3Header        = "b3"
+	b3TraceIDHeader = "x-b3-traceid"
+	b3SpanIDHeader  = "x-b3-spanid"
+	b3SampledHeader = "x-b3-sampled"
+	b3FlagsHeader   = "x-b3-flags"
+	b3ParentHeader  = "x-b3-parentspanid"
+)
+
+// B3Propagator reads and writes Zipkin's B3 headers. Extract accepts the single
+// b3 header and, when it is absent, the X-B3-* headers; Inject writes the
+// single header, or the X-B3-* headers when MultipleHeaders is set.
+type B3Propagator struct {
+	MultipleHeaders bool
+}
+
+var _ propagation.TextMapPropagator = B3Propagator{}
+
+func (p B3Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return
+	}
+	sampled := "0"
+	if spanCtx.IsSampled() {
+		sampled = "1"
+	}
+	if !p.MultipleHeaders {
+		carrier.Set(b3Header, spanCtx.TraceID().String()+"-"+spanCtx.SpanID().String()+"-"+sampled)
+		return
+	}
+	carrier.Set(b3TraceIDHeader, spanCtx.TraceID().String())
+	carrier.Set(b3SpanIDHeader, spanCtx.SpanID().String())
+	carrier.Set(b3SampledHeader, sampled)
+}
+
+func (p B3Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	var spanCtx trace.SpanContext
+	if header := carrier.Get(b3Header); header != "" {
+		spanCtx = extractB3Single(header)
+	} else {
+		spanCtx = extractB3Multi(carrier)
+	}
+	if !spanCtx.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, spanCtx)
+}
+
+func (p B3Propagator) Fields() []string {
+	if p.MultipleHeaders {
+		return []string{b3TraceIDHeader, b3SpanIDHeader, b3SampledHeader, b3FlagsHeader, b3ParentHeader}
+	}
+	return []string{b3Header}
+}
+
+// extractB3Single parses {TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]].
+// A header holding only the sampling state carries no trace to continue.
+func extractB3Single(header string) trace.SpanContext {
+	parts := strings.Split(header, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}
+	}
+	sampling := ""
+	if len(parts) > 2 {
+		sampling = parts[2]
+	}
+	return b3SpanContext(parts[0], parts[1], sampling)
+}
+
+func extractB3Multi(carrier propagation.TextMapCarrier) trace.SpanContext {
+	sampling := carrier.Get(b3SampledHeader)
+	if carrier.Get(b3FlagsHeader) == "1" {
+		sampling = "d"
+	}
+	return b3SpanContext(carrier.Get(b3TraceIDHeader), carrier.Get(b3SpanIDHeader), sampling)
+}
+
+// b3SpanContext builds the remote span context, widening 64-bit trace IDs to
+// the 128 bits OpenTelemetry uses. An unknown sampling state is not sampled.
+func b3SpanContext(traceIDHex, spanIDHex, sampling string) trace.SpanContext {
+	if len(traceIDHex) == 16 {
+		traceIDHex = strings.Repeat("0", 16) + traceIDHex
+	}
+	traceID, err := trace.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	spanID, err := trace.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	var flags trace.TraceFlags
+	switch strings.ToLower(sampling) {
+	case "1", "d", "true":
+		flags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+}
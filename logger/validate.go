@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ValidationError reports one invalid setting. Field is the YAML path relative to
+// the log section, e.g. "sampling.paths[0].rate".
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors collects every problem found by Config.Validate.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid log config: " + strings.Join(messages, "; ")
+}
+
+var validLevels = []string{"debug", "info", "warn", "error", "panic", "fatal"}
+
+// parseLevel is the one level parser behind Validate, Init, ComponentLevels and
+// SetLevel, so they agree on what is valid. Names are case-insensitive.
+func parseLevel(level string) (zapcore.Level, error) {
+	for _, valid := range validLevels {
+		if strings.EqualFold(level, valid) {
+			return zapcore.ParseLevel(valid)
+		}
+	}
+	return zapcore.InfoLevel, fmt.Errorf("unknown level %q, want one of %s", level, strings.Join(validLevels, ", "))
+}
+
+func isValidLevel(level string) bool {
+	_, err := parseLevel(level)
+	return err == nil
+}
+
+// Validate checks config without applying it. It returns nil or ValidationErrors
+// listing every invalid field. An empty level is allowed and means info.
+func (c Config) Validate() error {
+	var errs ValidationErrors
+	add := func(field string, format string, args ...any) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	levelMessage := "unknown level %q, want one of " + strings.Join(validLevels, ", ")
+
+	if c.Level != "" && !isValidLevel(c.Level) {
+		add("level", levelMessage, c.Level)
+	}
+
+	if c.FileEnabled && c.FilePath == "" {
+		add("filePath", "is required when fileEnabled is true")
+	}
+	for field, value := range map[string]int{"fileSize": c.FileSize, "maxAge": c.MaxAge, "maxBackups": c.MaxBackups} {
+		if value < 0 {
+			add(field, "must not be negative, got %d", value)
+		}
+	}
+
+	if c.Body.MaxRequestBytes < 0 {
+		add("body.maxRequestBytes", "must not be negative, got %d", c.Body.MaxRequestBytes)
+	}
+	if c.Body.MaxResponseBytes < 0 {
+		add("body.maxResponseBytes", "must not be negative, got %d", c.Body.MaxResponseBytes)
+	}
+
+	for pattern, level := range c.ComponentLevels {
+		field := fmt.Sprintf("componentLevels[%s]", pattern)
+		if !isValidLevel(level) {
+			add(field, levelMessage, level)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			add(field, "invalid pattern: %v", err)
+		}
+	}
+
+	if c.Sampling.Initial < 0 {
+		add("sampling.initial", "must not be negative, got %d", c.Sampling.Initial)
+	}
+	if c.Sampling.Thereafter < 0 {
+		add("sampling.thereafter", "must not be negative, got %d", c.Sampling.Thereafter)
+	}
+	for name, rate := range c.Sampling.Levels {
+		if !isValidLevel(name) {
+			add("sampling.levels."+name, levelMessage, name)
+		}
+		if rate < 0 || rate > 1 {
+			add("sampling.levels."+name, "rate %v is outside [0, 1]", rate)
+		}
+	}
+	for i, rule := range c.Sampling.Paths {
+		if rule.Rate < 0 || rule.Rate > 1 {
+			add(fmt.Sprintf("sampling.paths[%d].rate", i), "rate %v is outside [0, 1]", rule.Rate)
+		}
+	}
+
+	if c.Async.BufferSize < 0 {
+		add("async.bufferSize", "must not be negative, got %d", c.Async.BufferSize)
+	}
+	if !validOverflowPolicy(c.Async.Overflow) {
+		add("async.overflow", "unknown policy %q, want one of %s, %s, %s, %s", c.Async.Overflow,
+			OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel)
+	}
+	if c.Async.DropLevel != "" && !isValidLevel(c.Async.DropLevel) {
+		add("async.dropLevel", levelMessage, c.Async.DropLevel)
+	}
+	if c.Async.FlushInterval < 0 {
+		add("async.flushInterval", "must not be negative, got %s", c.Async.FlushInterval)
+	}
+
+	for i, name := range c.Tracing.Propagators {
+		if !validPropagator(name) {
+			add(fmt.Sprintf("tracing.propagators[%d]", i), "unknown propagator %q, want one of %s, %s, %s, %s", name,
+				PropagatorTraceContext, PropagatorBaggage, PropagatorB3, PropagatorB3Multi)
+		}
+	}
+
+	names := map[string]bool{}
+	for i, output := range c.Outputs {
+		field := fmt.Sprintf("outputs[%d]", i)
+		if output.Name != "" {
+			if names[output.Name] {
+				add(field+".name", "duplicate output name %q", output.Name)
+			}
+			names[output.Name] = true
+		}
+		if _, ok := lookupSink(output.Type); !ok {
+			add(field+".type", "unknown type %q, want one of %s", output.Type, strings.Join(SinkTypes(), ", "))
+		}
+		if output.Encoder != "" && !containsString(validEncoders, output.Encoder) {
+			add(field+".encoder", "unknown encoder %q, want one of %s", output.Encoder, strings.Join(validEncoders, ", "))
+		}
+		if output.Level != "" && !isValidLevel(output.Level) {
+			add(field+".level", levelMessage, output.Level)
+		}
+		switch output.Type {
+		case "file":
+			if output.Path == "" {
+				add(field+".path", "is required for file outputs")
+			}
+		case "tcp", "udp", "unix", "syslog", "otlp", "loki", "elasticsearch":
+			if output.Address == "" {
+				add(field+".address", "is required for %s outputs", output.Type)
+			}
+		}
+		if output.Type == "syslog" {
+			if output.Network != "" && !containsString(syslogNetworks, output.Network) {
+				add(field+".network", "unknown network %q, want one of %s", output.Network, strings.Join(syslogNetworks, ", "))
+			}
+			if _, ok := syslogFacilities[output.Facility]; output.Facility != "" && !ok {
+				add(field+".facility", "unknown facility %q", output.Facility)
+			}
+		}
+		if output.Compression != "" && !containsString(validCompressions, output.Compression) {
+			add(field+".compression", "unknown compression %q, want one of %s", output.Compression, strings.Join(validCompressions, ", "))
+		}
+		if !validOverflowPolicy(output.Overflow) {
+			add(field+".overflow", "unknown policy %q, want one of %s, %s, %s, %s", output.Overflow,
+				OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel)
+		}
+		if output.DropLevel != "" && !isValidLevel(output.DropLevel) {
+			add(field+".dropLevel", levelMessage, output.DropLevel)
+		}
+		if output.Type == "otlp" && output.Protocol != "" && !containsString(otlpProtocols, output.Protocol) {
+			add(field+".protocol", "unknown protocol %q, want one of %s", output.Protocol, strings.Join(otlpProtocols, ", "))
+		}
+	}
+
+	if c.Redaction != nil {
+		for i, rule := range c.Redaction.PathRules {
+			if err := validateStrategy(rule.Strategy); err != nil {
+				add(fmt.Sprintf("redaction.pathRules[%d].strategy", i), "%v", err)
+			}
+		}
+		for i, rule := range c.Redaction.FieldRules {
+			if err := validateStrategy(rule.Strategy); err != nil {
+				add(fmt.Sprintf("redaction.fieldRules[%d].strategy", i), "%v", err)
+			}
+		}
+		for i, rule := range c.Redaction.ValueRules {
+			if err := validateStrategy(rule.Strategy); err != nil {
+				add(fmt.Sprintf("redaction.valueRules[%d].strategy", i), "%v", err)
+			}
+			if _, builtin := BuiltinValuePatterns[rule.Pattern]; !builtin {
+				if _, err := regexp.Compile(rule.Pattern); err != nil {
+					add(fmt.Sprintf("redaction.valueRules[%d].pattern", i), "%v", err)
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	// Map iteration order is random; keep the report stable.
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/buffer"
@@ -26,67 +29,135 @@ func (c *CoolEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (
 	return c.Encoder.EncodeEntry(entry, filtered)
 }
 
-func newZapLogger(config Config) (*zap.Logger, *slog.Logger) {
-	zapLogLevel := getZapLogLevel(config.Level)
+// newZapLogger builds the cores of l. Output goes to stdout unless writer is set.
+func (l *Logger) newZapLogger(config Config, writer io.Writer) (*zap.Logger, *slog.Logger) {
+	// Cores accept every level; levelCore gates entries on the runtime registry.
+	zapLogLevel := zapcore.DebugLevel
 
-	lumberjackLogger := &lumberjack.Logger{
-		Filename:   config.FilePath,
-		MaxSize:    config.FileSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   true,
-	}
-
-	fileWriter := zapcore.AddSync(lumberjackLogger)
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-	jsonEncoder := zapcore.NewJSONEncoder(encoderConfig)
+	jsonEncoder := newEncoder("json")
 	zap.RegisterEncoder("cool", func(config zapcore.EncoderConfig) (zapcore.Encoder, error) {
 		return &CoolEncoder{jsonEncoder}, nil
 	})
 
-	encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-
-	zapCoreList := []zapcore.Core{}
-	if config.FileEnabled {
-		zapCoreList = append(zapCoreList, zapcore.NewCore(jsonEncoder, fileWriter, zapLogLevel))
+	var writerOutput Sink
+	if writer != nil {
+		writerOutput = NewWriterSink(writer)
 	}
 
-	if config.UseJSON {
-		zapCoreList = append(zapCoreList, zapcore.NewCore(jsonEncoder, zapcore.AddSync(os.Stdout), zapLogLevel))
+	var zapCoreList []zapcore.Core
+	var outputErr error
+	if len(config.Outputs) > 0 {
+		zapCoreList, outputErr = l.newOutputCores(config, writerOutput)
 	}
 
-	var core zapcore.Core
 	if len(zapCoreList) == 0 {
-		core = zapcore.NewTee(zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapLogLevel))
-	} else {
-		core = zapcore.NewTee(zapCoreList...)
+		output := writerOutput
+		if output == nil {
+			output = NewWriterSink(os.Stdout)
+		}
+
+		if config.FileEnabled {
+			fileWriter := newRotatingFile(config)
+			l.fileWriter = fileWriter
+			l.closers = append(l.closers, fileWriter.Close)
+			zapCoreList = append(zapCoreList, l.newOutputCore(config, jsonEncoder, NewWriterSink(fileWriter), zapLogLevel))
+		}
+
+		if config.UseJSON {
+			zapCoreList = append(zapCoreList, l.newOutputCore(config, jsonEncoder, output, zapLogLevel))
+		}
+
+		if len(zapCoreList) == 0 {
+			zapCoreList = append(zapCoreList, l.newOutputCore(config, newEncoder("console"), output, zapLogLevel))
+		}
 	}
 
+	core := zapcore.NewTee(zapCoreList...)
+	core = newLevelCore(core, l.levels)
+	l.startSamplingReporter(core, config.Sampling)
+	core = newSamplingCore(core, l)
+
 	zapLogger := zap.New(core, zap.AddCaller())
-	slogLogger := slog.New(NewOtelHandler(zapslog.NewHandler(core, zapslog.WithCaller(true))))
+	handler := NewOtelHandler(zapslog.NewHandler(core, zapslog.WithCaller(true)))
+	handler.owner = l
+	slogLogger := slog.New(handler)
+
+	if outputErr != nil {
+		slogLogger.ErrorContext(context.Background(), "invalid outputs, skipping them", "error", outputErr)
+	}
 
 	return zapLogger, slogLogger
 }
 
+// newOutputCore writes to out directly, or through a queue drained in the
+// background when config.Async is enabled. The queue is closed on Shutdown before
+// out itself.
+func (l *Logger) newOutputCore(config Config, enc zapcore.Encoder, out Sink, level zapcore.LevelEnabler) zapcore.Core {
+	if !config.Async.Enabled {
+		return newSinkCore(enc, out, level)
+	}
+	queue := newAsyncQueue(out, config.Async)
+	l.asyncQueues = append(l.asyncQueues, queue)
+	l.closers = append(l.closers, queue.close)
+	return newAsyncCore(enc, queue, level)
+}
+
+// rotatingFile lets the lumberjack rotation settings change while cores keep
+// writing to the same zapcore.WriteSyncer.
+type rotatingFile struct {
+	mu     sync.Mutex
+	logger *lumberjack.Logger
+	// closed drops later writes, which lumberjack would reopen the file for.
+	closed bool
+}
+
+func newRotatingFile(config Config) *rotatingFile {
+	return &rotatingFile{logger: newLumberjackLogger(config)}
+}
+
+func newLumberjackLogger(config Config) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   config.FilePath,
+		MaxSize:    config.FileSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAge,
+		Compress:   true,
+	}
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return len(p), nil
+	}
+	return f.logger.Write(p)
+}
+
+func (f *rotatingFile) Sync() error {
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.logger.Close()
+}
+
+func (f *rotatingFile) update(config Config) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous := f.logger
+	f.logger = newLumberjackLogger(config)
+	return previous.Close()
+}
+
 func getZapLogLevel(level string) zapcore.Level {
-	switch level {
-	case "debug":
-		return zap.DebugLevel
-	case "info":
-		return zap.InfoLevel
-	case "warn":
-		return zap.WarnLevel
-	case "error":
-		return zap.ErrorLevel
-	case "panic":
-		return zap.PanicLevel
-	case "fatal":
-		return zap.FatalLevel
-	default:
+	parsed, err := parseLevel(level)
+	if err != nil {
 		return zap.InfoLevel
 	}
+	return parsed
 }
\ No newline at end of file
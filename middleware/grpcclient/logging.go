@@ -9,6 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -18,12 +22,22 @@ import (
 )
 
 func UnaryClientLoggingInterceptor() grpc.UnaryClientInterceptor {
+	return UnaryClientLoggingInterceptorWithLogger(nil)
+}
+
+// UnaryClientLoggingInterceptorWithLogger logs through l; a nil l uses slog's
+// default logger, like UnaryClientLoggingInterceptor.
+func UnaryClientLoggingInterceptorWithLogger(l *logger.Logger) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, resp interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		startTime := time.Now()
+		slogger := slogFor(l)
+		ctx = forwardRequestID(ctx)
+		ctx, span := startClientSpan(ctx, method, l)
+		defer span.End()
 
 		sentMd, _ := metadata.FromOutgoingContext(ctx)
 
-		logGRPCClientRequest(ctx, method, sentMd, req)
+		logGRPCClientRequest(ctx, slogger, method, sentMd, req)
 
 		var receivedMd metadata.MD
 		opts = append(opts, grpc.Header(&receivedMd))
@@ -36,13 +50,14 @@ func UnaryClientLoggingInterceptor() grpc.UnaryClientInterceptor {
 			statusCode = status.Code(err)
 		}
 
-		logGRPCClientResponse(ctx, method, receivedMd, startTime, resp, statusCode, statusError)
+		endClientSpan(span, err)
+		logGRPCClientResponse(ctx, slogger, method, receivedMd, startTime, resp, statusCode, statusError)
 
 		return err
 	}
 }
 
-func logGRPCClientRequest(ctx context.Context, method string, md metadata.MD, req any) {
+func logGRPCClientRequest(ctx context.Context, slogger *slog.Logger, method string, md metadata.MD, req any) {
 	var reqMap map[string]interface{}
 	var reqMapErr error
 
@@ -50,7 +65,7 @@ func logGRPCClientRequest(ctx context.Context, method string, md metadata.MD, re
 	if ok {
 		reqMap, reqMapErr = protoMessageToMap(reqProto)
 		if reqMapErr != nil {
-			slog.WarnContext(ctx, "failed to convert request to map", "error", reqMapErr)
+			slogger.WarnContext(ctx, "failed to convert request to map", "error", reqMapErr)
 		}
 	}
 
@@ -61,10 +76,10 @@ func logGRPCClientRequest(ctx context.Context, method string, md metadata.MD, re
 		slog.Any("body", reqMap),
 	}
 
-	slog.InfoContext(ctx, fmt.Sprintf("Sent gRPC Request to %s", method), fields...)
+	slogger.InfoContext(ctx, fmt.Sprintf("Sent gRPC Request to %s", method), fields...)
 }
 
-func logGRPCClientResponse(ctx context.Context, method string, md metadata.MD, startTime time.Time, resp interface{}, statusCode codes.Code, statusError any) {
+func logGRPCClientResponse(ctx context.Context, slogger *slog.Logger, method string, md metadata.MD, startTime time.Time, resp interface{}, statusCode codes.Code, statusError any) {
 	var respMap map[string]interface{}
 	var respMapErr error
 
@@ -72,7 +87,7 @@ func logGRPCClientResponse(ctx context.Context, method string, md metadata.MD, s
 	if ok {
 		respMap, respMapErr = protoMessageToMap(respProto)
 		if respMapErr != nil {
-			slog.WarnContext(ctx, "failed to convert response to map", "error", respMapErr)
+			slogger.WarnContext(ctx, "failed to convert response to map", "error", respMapErr)
 		}
 	}
 
@@ -88,10 +103,58 @@ func logGRPCClientResponse(ctx context.Context, method string, md metadata.MD, s
 
 	msg := fmt.Sprintf("Received gRPC Response from %s", method)
 	if statusError != nil {
-		slog.ErrorContext(ctx, msg, fields...)
+		slogger.ErrorContext(ctx, msg, fields...)
 	} else {
-		slog.InfoContext(ctx, msg, fields...)
+		slogger.InfoContext(ctx, msg, fields...)
+	}
+}
+
+// forwardRequestID adds the request ID of ctx to the outgoing metadata, unless
+// the caller already set one, so the server logs the same ID.
+func forwardRequestID(ctx context.Context) context.Context {
+	requestID := logger.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(logger.RequestIDMetadataKey)) > 0 {
+		return ctx
 	}
+	return metadata.AppendToOutgoingContext(ctx, logger.RequestIDMetadataKey, requestID)
+}
+
+// startClientSpan starts a client span for the call and adds it to the outgoing
+// metadata, when l, or the default Logger for a nil l, has tracing enabled.
+func startClientSpan(ctx context.Context, fullMethod string, l *logger.Logger) (context.Context, trace.Span) {
+	if l == nil {
+		l = logger.Default()
+	}
+	if !l.TracingEnabled() {
+		return l.StartClientSpan(ctx, fullMethod, nil)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	ctx, span := l.StartClientSpan(ctx, strings.TrimPrefix(fullMethod, "/"), logger.MetadataCarrier(md),
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	)
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endClientSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+}
+
+// slogFor resolves the logger on every call so a nil l follows slog.SetDefault.
+func slogFor(l *logger.Logger) *slog.Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l.Slog()
 }
 
 func protoMessageToMap(message proto.Message) (map[string]interface{}, error) {
@@ -111,43 +174,10 @@ func protoMessageToMap(message proto.Message) (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	maskSensitiveDataUsingStructTag(message, result)
+	logger.MaskSensitiveFields(message, result)
 	return result, nil
 }
 
-func maskSensitiveDataUsingStructTag(message proto.Message, data map[string]interface{}) {
-	value := reflect.ValueOf(message)
-	if !value.IsValid() || value.IsZero() {
-		return
-	}
-
-	value = value.Elem()
-	typeOf := value.Type()
-
-	for i := 0; i < value.NumField(); i++ {
-		field := typeOf.Field(i)
-
-		if sensitiveTag, ok := field.Tag.Lookup("sensitive"); ok && sensitiveTag == "true" {
-			jsonTag := field.Tag.Get("json")
-			jsonFieldName := jsonTag
-			if commaIdx := strings.Index(jsonTag, ","); commaIdx > -1 {
-				jsonFieldName = jsonTag[:commaIdx]
-			}
-
-			if value, exists := data[jsonFieldName]; exists {
-				strValue, ok := value.(string)
-				if ok {
-					if len(strValue) >= 2 {
-						data[jsonFieldName] = strValue[0:1] + "*****" + strValue[len(strValue)-1:]
-					} else if len(strValue) == 1 {
-						data[jsonFieldName] = strValue + "*****"
-					}
-				}
-			}
-		}
-	}
-}
-
 func GRPCClientInterceptor() grpc.UnaryClientInterceptor {
 	return UnaryClientLoggingInterceptor()
 }
\ No newline at end of file
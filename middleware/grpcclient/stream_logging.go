@@ -0,0 +1,225 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func StreamClientLoggingInterceptor() grpc.StreamClientInterceptor {
+	return StreamClientLoggingInterceptorWithLogger(nil)
+}
+
+// StreamClientLoggingInterceptorWithLogger logs through l; a nil l uses slog's
+// default logger, like StreamClientLoggingInterceptor.
+func StreamClientLoggingInterceptorWithLogger(l *logger.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		startTime := time.Now()
+		slogger := slogFor(l)
+		ctx = forwardRequestID(ctx)
+		ctx, span := startClientSpan(ctx, method, l)
+
+		sentMd, _ := metadata.FromOutgoingContext(ctx)
+
+		logGRPCClientStreamOpen(ctx, slogger, method, desc, sentMd)
+
+		clientStream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endClientSpan(span, err)
+			span.End()
+			logGRPCClientStreamClose(ctx, slogger, method, nil, nil, startTime, status.Code(err), err, 0, 0)
+			return nil, err
+		}
+
+		stream := &loggingClientStream{
+			ClientStream:  clientStream,
+			ctx:           ctx,
+			span:          span,
+			logger:        slogger,
+			method:        method,
+			serverStreams: desc.ServerStreams,
+			startTime:     startTime,
+			done:          make(chan struct{}),
+		}
+		// A caller that cancels ctx or stops reading never sees the final
+		// RecvMsg error, so the stream is also finished when ctx ends.
+		if ctx.Done() != nil {
+			go stream.finishOnCancel()
+		}
+		return stream, nil
+	}
+}
+
+// loggingClientStream logs every message crossing the stream and a closing entry once
+// the final status is known, which is when RecvMsg returns an error, for streams
+// where the server replies once after the single response has been received, or
+// when the call's context ends first.
+type loggingClientStream struct {
+	grpc.ClientStream
+	ctx           context.Context
+	span          trace.Span
+	logger        *slog.Logger
+	method        string
+	serverStreams bool
+	startTime     time.Time
+
+	mu           sync.Mutex
+	msgsSent     int
+	msgsReceived int
+	finishOnce   sync.Once
+	// done is closed once the stream is finished.
+	done chan struct{}
+}
+
+func (s *loggingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.msgsSent++
+	sequence := s.msgsSent
+	s.mu.Unlock()
+
+	logGRPCClientStreamMessage(s.ctx, s.logger, fmt.Sprintf("Sent gRPC stream message to %s", s.method), s.method, sequence, m)
+	return nil
+}
+
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.finish(codes.OK, nil)
+		} else {
+			s.finish(status.Code(err), err)
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	s.msgsReceived++
+	sequence := s.msgsReceived
+	s.mu.Unlock()
+
+	logGRPCClientStreamMessage(s.ctx, s.logger, fmt.Sprintf("Received gRPC stream message from %s", s.method), s.method, sequence, m)
+
+	if !s.serverStreams {
+		s.finish(codes.OK, nil)
+	}
+	return nil
+}
+
+func (s *loggingClientStream) finish(statusCode codes.Code, statusError error) {
+	s.finishWith(statusCode, statusError, true)
+}
+
+func (s *loggingClientStream) finishOnCancel() {
+	select {
+	case <-s.ctx.Done():
+		// The transport may still be writing the header and trailer, which
+		// are only safe to read once RecvMsg has returned, so they are left
+		// out of the closing entry.
+		err := status.FromContextError(s.ctx.Err()).Err()
+		s.finishWith(status.Code(err), err, false)
+	case <-s.done:
+	}
+}
+
+func (s *loggingClientStream) finishWith(statusCode codes.Code, statusError error, readMetadata bool) {
+	s.finishOnce.Do(func() {
+		defer close(s.done)
+
+		s.mu.Lock()
+		msgsSent, msgsReceived := s.msgsSent, s.msgsReceived
+		s.mu.Unlock()
+
+		var headerMd, trailerMd metadata.MD
+		if readMetadata {
+			if statusError == nil {
+				headerMd, _ = s.ClientStream.Header()
+			}
+			trailerMd = s.ClientStream.Trailer()
+		}
+
+		logGRPCClientStreamClose(s.ctx, s.logger, s.method, headerMd, trailerMd, s.startTime, statusCode, statusError, msgsSent, msgsReceived)
+		endClientSpan(s.span, statusError)
+		s.span.End()
+	})
+}
+
+func logGRPCClientStreamOpen(ctx context.Context, slogger *slog.Logger, method string, desc *grpc.StreamDesc, md metadata.MD) {
+	fields := []any{
+		slog.String("type", "grpcclient_stream"),
+		slog.String("method", method),
+		slog.Any("metadata", md),
+		slog.Bool("client_stream", desc.ClientStreams),
+		slog.Bool("server_stream", desc.ServerStreams),
+	}
+
+	slogger.InfoContext(ctx, fmt.Sprintf("Opened gRPC stream to %s", method), fields...)
+}
+
+func logGRPCClientStreamMessage(ctx context.Context, slogger *slog.Logger, msg string, method string, sequence int, message interface{}) {
+	var bodyMap map[string]interface{}
+	var bodyMapErr error
+
+	messageProto, ok := message.(proto.Message)
+	if ok {
+		bodyMap, bodyMapErr = protoMessageToMap(messageProto)
+		if bodyMapErr != nil {
+			slogger.WarnContext(ctx, "failed to convert stream message to map", "error", bodyMapErr)
+		}
+	}
+
+	fields := []any{
+		slog.String("type", "grpcclient_stream"),
+		slog.String("method", method),
+		slog.Int("sequence", sequence),
+		slog.Any("body", bodyMap),
+	}
+
+	slogger.DebugContext(ctx, msg, fields...)
+}
+
+func logGRPCClientStreamClose(ctx context.Context, slogger *slog.Logger, method string, headerMd metadata.MD, trailerMd metadata.MD, startTime time.Time, statusCode codes.Code, statusError error, msgsSent int, msgsReceived int) {
+	var errField any
+	if statusError != nil {
+		errField = statusError
+	}
+
+	fields := []any{
+		slog.String("type", "grpcclient_stream"),
+		slog.String("method", method),
+		slog.Any("metadata", headerMd),
+		slog.Any("trailer", trailerMd),
+		slog.Any("status_code", statusCode),
+		slog.Any("error", errField),
+		slog.Int("msgs_sent", msgsSent),
+		slog.Int("msgs_received", msgsReceived),
+		slog.String("duration", time.Since(startTime).String()),
+	}
+
+	msg := fmt.Sprintf("Closed gRPC stream from %s", method)
+	if statusError != nil {
+		slogger.ErrorContext(ctx, msg, fields...)
+	} else {
+		slogger.InfoContext(ctx, msg, fields...)
+	}
+}
+
+func GRPCClientStreamInterceptor() grpc.StreamClientInterceptor {
+	return StreamClientLoggingInterceptor()
+}
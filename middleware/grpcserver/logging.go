@@ -4,10 +4,15 @@ import (
 	"context"
 	"log/slog"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -19,6 +24,8 @@ type LoggerInterceptor interface {
 
 type loggerInterceptor struct {
 	logger slog.Logger
+	// owner is nil for the default Logger.
+	owner *logger.Logger
 }
 
 func NewUnaryLoggerInterceptor(slogger slog.Logger) LoggerInterceptor {
@@ -28,6 +35,15 @@ func NewUnaryLoggerInterceptor(slogger slog.Logger) LoggerInterceptor {
 	}
 }
 
+// NewUnaryLoggerInterceptorWithLogger logs each unary RPC through l rather
+// than the default Logger, and starts its server span from the incoming
+// metadata with l's propagators.
+func NewUnaryLoggerInterceptorWithLogger(l *logger.Logger) LoggerInterceptor {
+	interceptor := NewUnaryLoggerInterceptor(*l.Slog()).(*loggerInterceptor)
+	interceptor.owner = l
+	return interceptor
+}
+
 func (l *loggerInterceptor) Intercept() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		if info.FullMethod == "/grpc.health.v1.Health/Check" {
@@ -35,12 +51,16 @@ func (l *loggerInterceptor) Intercept() grpc.UnaryServerInterceptor {
 		}
 
 		startTime := time.Now()
+		ctx, span := startServerSpan(ctx, info.FullMethod, l.owner)
+		defer span.End()
+		ctx = methodContext(ctx, info.FullMethod, l.owner)
 
 		reqProto, _ := req.(proto.Message)
 		requestBody, _ := protoMessageToJsonBytes(reqProto)
 
 		resp, err := handler(ctx, req)
 		elapse := time.Since(startTime)
+		endServerSpan(span, err)
 		respProto, _ := resp.(proto.Message)
 		responseBody, _ := protoMessageToJsonBytes(respProto)
 
@@ -62,7 +82,11 @@ func (l *loggerInterceptor) Intercept() grpc.UnaryServerInterceptor {
 			level = logger.Info
 		}
 
-		logger.CanonicalLogger(
+		canonicalLogger := logger.CanonicalLogger
+		if l.owner != nil {
+			canonicalLogger = l.owner.CanonicalLogger
+		}
+		canonicalLogger(
 			ctx,
 			l.logger,
 			level,
@@ -83,6 +107,60 @@ func (l *loggerInterceptor) Intercept() grpc.UnaryServerInterceptor {
 	}
 }
 
+// methodContext attaches the method as the route, the request ID, and the slog
+// logger of owner or of the default Logger, to ctx. The request ID comes from the
+// x-request-id metadata, or is generated, and is returned in the response header.
+func methodContext(ctx context.Context, fullMethod string, owner *logger.Logger) context.Context {
+	if owner == nil {
+		owner = logger.Default()
+	}
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(logger.RequestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	requestID = logger.RequestIDOrNew(requestID)
+	// Fails only outside a real call, such as when the interceptor is invoked
+	// directly, where there is no header to set.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDMetadataKey, requestID))
+
+	ctx = logger.ContextWithAttrs(ctx, slog.String(logger.RouteKey, fullMethod), slog.String(logger.RequestIDKey, requestID))
+	return logger.WithContext(ctx, owner.Slog())
+}
+
+// startServerSpan continues the caller's trace from the incoming metadata in a
+// server span named after the method, when owner has tracing enabled.
+func startServerSpan(ctx context.Context, fullMethod string, owner *logger.Logger) (context.Context, trace.Span) {
+	if owner == nil {
+		owner = logger.Default()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	service, method := splitFullMethod(fullMethod)
+	return owner.StartServerSpan(ctx, strings.TrimPrefix(fullMethod, "/"), logger.MetadataCarrier(md),
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	)
+}
+
+func endServerSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+}
+
+// splitFullMethod splits /package.Service/Method into its service and method.
+func splitFullMethod(fullMethod string) (string, string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return "", service
+	}
+	return service, method
+}
+
 func protoMessageToJsonBytes(message proto.Message) ([]byte, error) {
 	if message == nil || reflect.ValueOf(message).IsNil() {
 		return nil, nil
@@ -93,7 +171,7 @@ func protoMessageToJsonBytes(message proto.Message) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return jsonBytes, nil
+	return logger.MaskSensitiveJSON(message, jsonBytes), nil
 }
 
 func GRPCServerInterceptor() grpc.UnaryServerInterceptor {
@@ -0,0 +1,238 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type StreamLoggerInterceptor interface {
+	InterceptStream() grpc.StreamServerInterceptor
+}
+
+type streamLoggerInterceptor struct {
+	logger      slog.Logger
+	logMessages bool
+	// owner is nil for the default Logger.
+	owner *logger.Logger
+}
+
+// NewStreamLoggerInterceptor emits one canonical log per stream. When logMessages
+// is true every received and sent message is also logged at debug level.
+func NewStreamLoggerInterceptor(slogger slog.Logger, logMessages bool) StreamLoggerInterceptor {
+	loggerWithName := slogger.With(slog.String("logger_name", "grpc_interceptor"))
+	return &streamLoggerInterceptor{
+		logger:      *loggerWithName,
+		logMessages: logMessages,
+	}
+}
+
+// NewStreamLoggerInterceptorWithLogger logs each server stream, and its
+// messages when logMessages is set, through l rather than the default Logger.
+func NewStreamLoggerInterceptorWithLogger(l *logger.Logger, logMessages bool) StreamLoggerInterceptor {
+	interceptor := NewStreamLoggerInterceptor(*l.Slog(), logMessages).(*streamLoggerInterceptor)
+	interceptor.owner = l
+	return interceptor
+}
+
+func (l *streamLoggerInterceptor) InterceptStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod == "/grpc.health.v1.Health/Watch" {
+			return handler(srv, ss)
+		}
+
+		startTime := time.Now()
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod, l.owner)
+		defer span.End()
+		stream := &loggingServerStream{
+			ServerStream: ss,
+			ctx:          methodContext(ctx, info.FullMethod, l.owner),
+			logger:       &l.logger,
+			method:       info.FullMethod,
+			logMessages:  l.logMessages,
+			owner:        l.owner,
+		}
+
+		err := handler(srv, stream)
+		elapse := time.Since(startTime)
+		endServerSpan(span, err)
+		ctx = stream.Context()
+		stats := stream.snapshot()
+		lastSent, _ := protoMessageToJsonBytes(stats.lastSent)
+
+		var fields []any
+		fields = append(fields,
+			slog.String("logger_name", "canonical"),
+			slog.Group("grpcserver_md",
+				slog.String("type", "grpcserver_stream"),
+				slog.String("method", "STREAM"),
+				slog.String("path", info.FullMethod),
+				slog.String("duration", elapse.String()),
+				slog.Bool("client_stream", info.IsClientStream),
+				slog.Bool("server_stream", info.IsServerStream),
+				slog.Int("msgs_received", stats.msgsReceived),
+				slog.Int("msgs_sent", stats.msgsSent),
+				slog.Int("bytes_received", stats.bytesReceived),
+				slog.Int("bytes_sent", stats.bytesSent),
+			),
+		)
+
+		var level logger.Level
+		if err != nil {
+			level = logger.Error
+		} else {
+			level = logger.Info
+		}
+
+		canonicalLogger := logger.CanonicalLogger
+		if l.owner != nil {
+			canonicalLogger = l.owner.CanonicalLogger
+		}
+		canonicalLogger(
+			ctx,
+			l.logger,
+			level,
+			stats.firstReceived,
+			lastSent,
+			err,
+			logger.CanonicalLog{
+				Transport: "grpc",
+				Traffic:   "internal",
+				Method:    "STREAM",
+				Status:    int(status.Code(err)),
+				Path:      info.FullMethod,
+				Duration:  elapse,
+			},
+			fields,
+		)
+		return err
+	}
+}
+
+type streamStats struct {
+	msgsReceived  int
+	msgsSent      int
+	bytesReceived int
+	bytesSent     int
+	firstReceived []byte
+	// lastSent is a copy, marshalled only for the canonical entry.
+	lastSent proto.Message
+}
+
+// loggingServerStream counts traffic on a server stream. SendMsg and RecvMsg may be
+// called from different goroutines, so the stats are guarded by a mutex.
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	logger      *slog.Logger
+	method      string
+	logMessages bool
+	// owner is nil for the default Logger.
+	owner *logger.Logger
+
+	mu    sync.Mutex
+	stats streamStats
+}
+
+// Context is the stream's context with the route attached, which handlers see.
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	msgProto, _ := m.(proto.Message)
+
+	s.mu.Lock()
+	s.stats.msgsReceived++
+	s.stats.bytesReceived += protoMessageSize(msgProto)
+	sequence := s.stats.msgsReceived
+	s.mu.Unlock()
+
+	// Only the first message goes into the canonical entry, so the others are
+	// marshalled only when they are logged themselves.
+	var body []byte
+	if sequence == 1 || s.logMessages {
+		body, _ = protoMessageToJsonBytes(msgProto)
+	}
+	if sequence == 1 {
+		s.mu.Lock()
+		s.stats.firstReceived = body
+		s.mu.Unlock()
+	}
+
+	if s.logMessages {
+		s.logMessage(fmt.Sprintf("Received gRPC stream message on %s", s.method), sequence, body)
+	}
+	return nil
+}
+
+func (s *loggingServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+
+	msgProto, _ := m.(proto.Message)
+	// The handler may reuse m once SendMsg returns.
+	lastSent := proto.Clone(msgProto)
+
+	s.mu.Lock()
+	s.stats.msgsSent++
+	s.stats.bytesSent += protoMessageSize(msgProto)
+	s.stats.lastSent = lastSent
+	sequence := s.stats.msgsSent
+	s.mu.Unlock()
+
+	if s.logMessages {
+		body, _ := protoMessageToJsonBytes(msgProto)
+		s.logMessage(fmt.Sprintf("Sent gRPC stream message on %s", s.method), sequence, body)
+	}
+	return nil
+}
+
+func (s *loggingServerStream) snapshot() streamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// logMessage redacts body like the canonical log does, with the path and field
+// rules of the owner's Redactor for the method.
+func (s *loggingServerStream) logMessage(msg string, sequence int, body []byte) {
+	owner := s.owner
+	if owner == nil {
+		owner = logger.Default()
+	}
+
+	s.logger.DebugContext(s.Context(), msg,
+		slog.String("type", "grpcserver_stream"),
+		slog.String("path", s.method),
+		slog.Int("sequence", sequence),
+		slog.Any("body", owner.Redactor().RedactBody(s.method, body)),
+	)
+}
+
+func protoMessageSize(message proto.Message) int {
+	if message == nil || reflect.ValueOf(message).IsNil() {
+		return 0
+	}
+	return proto.Size(message)
+}
+
+func GRPCServerStreamInterceptor() grpc.StreamServerInterceptor {
+	if logger.Slog == nil {
+		panic("Logger not initialized. Call logger.Init() first.")
+	}
+	return NewStreamLoggerInterceptor(*logger.Slog, false).InterceptStream()
+}
@@ -0,0 +1,312 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Config struct {
+	// MaxBodyBytes caps how much of each request and response body is captured.
+	// Zero disables body capture.
+	MaxBodyBytes int
+	// AllowedHeaders lists the only headers copied into the log entries.
+	AllowedHeaders []string
+	// Logger receives the entries, with bodies redacted by its Redactor; nil
+	// uses slog's default logger and the default Logger's Redactor.
+	Logger *logger.Logger
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxBodyBytes: 4096,
+		AllowedHeaders: []string{
+			"Content-Type",
+			"Content-Length",
+			"Accept",
+			"User-Agent",
+			"X-Request-Id",
+		},
+	}
+}
+
+type loggingRoundTripper struct {
+	next           http.RoundTripper
+	maxBodyBytes   int
+	allowedHeaders []string
+	logger         *logger.Logger
+}
+
+func NewLoggingRoundTripper(next http.RoundTripper, config Config) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	allowedHeaders := make([]string, 0, len(config.AllowedHeaders))
+	for _, header := range config.AllowedHeaders {
+		allowedHeaders = append(allowedHeaders, http.CanonicalHeaderKey(header))
+	}
+
+	return &loggingRoundTripper{
+		next:           next,
+		maxBodyBytes:   config.MaxBodyBytes,
+		allowedHeaders: allowedHeaders,
+		logger:         config.Logger,
+	}
+}
+
+func (l *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+	ctx := req.Context()
+	slogger := slog.Default()
+	if l.logger != nil {
+		slogger = l.logger.Slog()
+	}
+
+	// The request ID of ctx is forwarded so the called service logs it too.
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" && req.Header.Get(logger.RequestIDHeader) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(logger.RequestIDHeader, requestID)
+	}
+
+	owner := l.logger
+	if owner == nil {
+		owner = logger.Default()
+	}
+	var span trace.Span
+	if owner.TracingEnabled() {
+		header := req.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		ctx, span = owner.StartClientSpan(ctx, req.Method, propagation.HeaderCarrier(header),
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.full", req.URL.Redacted()),
+		)
+		req = req.Clone(ctx)
+		req.Header = header
+	}
+
+	var reqBody capturedBody
+	if req.Body != nil && req.Body != http.NoBody && l.maxBodyBytes > 0 {
+		req = req.Clone(ctx)
+		reqBody, req.Body = captureBody(req.Body, l.maxBodyBytes)
+	}
+
+	logHTTPClientRequest(ctx, slogger, owner.Redactor(), req, l.filterHeaders(req.Header), reqBody)
+
+	resp, err := l.next.RoundTrip(req)
+	finish := func(body capturedBody) {
+		if span != nil {
+			endClientSpan(span, resp, err)
+			span.End()
+		}
+		logHTTPClientResponse(ctx, slogger, owner.Redactor(), req, resp, l.filterHeaders(responseHeader(resp)), body, startTime, err)
+	}
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		finish(capturedBody{})
+		return resp, err
+	}
+
+	// The response is logged, and the span ended, once the caller has read the
+	// body to EOF or closed it, so streamed responses are not held back.
+	limit := l.maxBodyBytes
+	if isStreamingResponse(resp) {
+		limit = 0
+	}
+	resp.Body = &loggedBody{body: resp.Body, limit: limit, contentLength: resp.ContentLength, finish: finish}
+	return resp, err
+}
+
+// endClientSpan records the response status; client and server errors and
+// transport failures mark the span as failed.
+func endClientSpan(span trace.Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+}
+
+func (l *loggingRoundTripper) filterHeaders(headers http.Header) map[string]string {
+	filtered := make(map[string]string, len(l.allowedHeaders))
+	for _, key := range l.allowedHeaders {
+		if value := headers.Get(key); value != "" {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+type capturedBody struct {
+	data      []byte
+	truncated bool
+}
+
+// captureBody reads at most limit bytes and returns a body that replays them before
+// continuing with the unread remainder, so the caller still sees the full stream.
+func captureBody(body io.ReadCloser, limit int) (capturedBody, io.ReadCloser) {
+	prefix, _ := io.ReadAll(io.LimitReader(body, int64(limit+1)))
+
+	captured := capturedBody{data: prefix}
+	if len(prefix) > limit {
+		captured.data = prefix[:limit]
+		captured.truncated = true
+	}
+
+	return captured, &replayBody{
+		Reader: io.MultiReader(bytes.NewReader(prefix), body),
+		closer: body,
+	}
+}
+
+type replayBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *replayBody) Close() error {
+	return r.closer.Close()
+}
+
+// loggedBody keeps the first limit bytes the caller reads and calls finish with
+// them at EOF or on Close, whichever comes first.
+type loggedBody struct {
+	body          io.ReadCloser
+	limit         int
+	contentLength int64
+	finish        func(capturedBody)
+
+	mu       sync.Mutex
+	data     []byte
+	read     int64
+	finished bool
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+
+	b.mu.Lock()
+	if keep := min(n, b.limit+1-len(b.data)); b.limit > 0 && keep > 0 {
+		b.data = append(b.data, p[:keep]...)
+	}
+	b.read += int64(n)
+	b.mu.Unlock()
+
+	if err == io.EOF {
+		b.done(true)
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.body.Close()
+	b.done(false)
+	return err
+}
+
+func (b *loggedBody) done(eof bool) {
+	b.mu.Lock()
+	if b.finished {
+		b.mu.Unlock()
+		return
+	}
+	b.finished = true
+	var captured capturedBody
+	if b.limit > 0 {
+		captured.data = append([]byte{}, b.data[:min(len(b.data), b.limit)]...)
+		// A body closed before the end was only partly seen.
+		captured.truncated = len(b.data) > b.limit || (!eof && b.read != b.contentLength)
+	}
+	b.mu.Unlock()
+
+	b.finish(captured)
+}
+
+func isStreamingResponse(resp *http.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+}
+
+func responseHeader(resp *http.Response) http.Header {
+	if resp == nil {
+		return nil
+	}
+	return resp.Header
+}
+
+// bodyField redacts body with the field and value rules of redactor. A
+// truncated body is no longer valid JSON, so only the value rules apply to it.
+func bodyField(body capturedBody, redactor *logger.Redactor) slog.Attr {
+	if body.data == nil {
+		return slog.Any("body", nil)
+	}
+	return slog.Any("body", redactor.RedactPayload(body.data))
+}
+
+func logHTTPClientRequest(ctx context.Context, slogger *slog.Logger, redactor *logger.Redactor, req *http.Request, headers map[string]string, body capturedBody) {
+	fields := []any{
+		slog.String("type", "httpclient"),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Any("headers", headers),
+		bodyField(body, redactor),
+		slog.Bool("body_truncated", body.truncated),
+	}
+
+	slogger.InfoContext(ctx, fmt.Sprintf("Sent HTTP Request to %s %s", req.Method, req.URL.Redacted()), fields...)
+}
+
+func logHTTPClientResponse(ctx context.Context, slogger *slog.Logger, redactor *logger.Redactor, req *http.Request, resp *http.Response, headers map[string]string, body capturedBody, startTime time.Time, err error) {
+	var statusCode int
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+
+	var errField any
+	if err != nil {
+		errField = err.Error()
+	}
+
+	fields := []any{
+		slog.String("type", "httpclient"),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+		slog.Int("status_code", statusCode),
+		slog.Any("headers", headers),
+		bodyField(body, redactor),
+		slog.Bool("body_truncated", body.truncated),
+		slog.Any("error", errField),
+		slog.String("duration", time.Since(startTime).String()),
+	}
+
+	msg := fmt.Sprintf("Received HTTP Response from %s %s", req.Method, req.URL.Redacted())
+	switch {
+	case err != nil || statusCode >= http.StatusInternalServerError:
+		slogger.ErrorContext(ctx, msg, fields...)
+	case statusCode >= http.StatusBadRequest:
+		slogger.WarnContext(ctx, msg, fields...)
+	default:
+		slogger.InfoContext(ctx, msg, fields...)
+	}
+}
+
+func HTTPClientRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return NewLoggingRoundTripper(next, DefaultConfig())
+}
@@ -1,7 +1,6 @@
 package httpserver
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -9,6 +8,9 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func convertHeaderAttrToString(key string, headers map[string][]string) string {
@@ -18,12 +20,72 @@ func convertHeaderAttrToString(key string, headers map[string][]string) string {
 	return ""
 }
 
+const (
+	requestModelKey  = "blogger_request_model"
+	responseModelKey = "blogger_response_model"
+)
+
+// SetRequestModel registers the value the request body was decoded into, so fields
+// tagged `sensitive:"true"` are masked in the canonical log.
+func SetRequestModel(c *fiber.Ctx, model any) {
+	c.Locals(requestModelKey, model)
+}
+
+// SetResponseModel registers the value sent as the response body, so fields tagged
+// `sensitive:"true"` are masked in the canonical log.
+func SetResponseModel(c *fiber.Ctx, model any) {
+	c.Locals(responseModelKey, model)
+}
+
+func maskBodyWithModel(model any, body []byte) []byte {
+	if model == nil {
+		return body
+	}
+	return logger.MaskSensitiveJSON(model, body)
+}
+
+// fiberHeaderCarrier exposes the request headers to the trace propagators.
+type fiberHeaderCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h fiberHeaderCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h fiberHeaderCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h fiberHeaderCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// endHTTPServerSpan names the span after the matched route and records the
+// status; only server errors mark the span as failed.
+func endHTTPServerSpan(span trace.Span, method, route string, statusCode int) {
+	if route != "" {
+		span.SetName(method + " " + route)
+		span.SetAttributes(attribute.String("http.route", route))
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+}
+
 type LoggingMiddleware interface {
 	Logging() fiber.Handler
 }
 
 type loggingMiddleware struct {
 	logger slog.Logger
+	// owner is nil for the default Logger.
+	owner *logger.Logger
 }
 
 func NewLoggingMiddleware(slogger slog.Logger) LoggingMiddleware {
@@ -33,15 +95,40 @@ func NewLoggingMiddleware(slogger slog.Logger) LoggingMiddleware {
 	}
 }
 
+// NewLoggingMiddlewareWithLogger logs each Fiber request through l rather than
+// the default Logger. Handlers find l's slog logger in c.UserContext(), and
+// server spans follow l's tracing setup.
+func NewLoggingMiddlewareWithLogger(l *logger.Logger) LoggingMiddleware {
+	middleware := NewLoggingMiddleware(*l.Slog()).(*loggingMiddleware)
+	middleware.owner = l
+	return middleware
+}
+
 func (l *loggingMiddleware) Logging() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		startTime := time.Now()
 		requestBody := c.Body()
 
-		// Set up a custom context for the request
-		ctx := context.WithValue(c.UserContext(), "middleware", "http")
+		owner := l.owner
+		if owner == nil {
+			owner = logger.Default()
+		}
+		// The route is only known once the request is routed, so the span is
+		// renamed after c.Next.
+		ctx, span := owner.StartServerSpan(c.UserContext(), c.Method(), fiberHeaderCarrier{c},
+			attribute.String("http.request.method", c.Method()),
+			attribute.String("url.path", c.Path()),
+		)
+		defer span.End()
 		c.SetUserContext(ctx)
 
+		// The request ID is generated when the caller sent none, and returned
+		// so the caller can quote it. Handlers logging with c.UserContext() get
+		// the request and user IDs.
+		requestID := logger.RequestIDOrNew(c.Get(logger.RequestIDHeader))
+		c.Set(logger.RequestIDHeader, requestID)
+		c.SetUserContext(logger.RequestContext(c.UserContext(), l.owner, requestID, c.Get("X-User-Id")))
+
 		// Add panic recovery
 		defer func() {
 			if r := recover(); r != nil {
@@ -51,7 +138,9 @@ func (l *loggingMiddleware) Logging() fiber.Handler {
 
 		err := c.Next()
 		elapse := time.Since(startTime)
-		responseBody := c.Response().Body()
+		endHTTPServerSpan(span, c.Method(), c.Route().Path, c.Response().StatusCode())
+		responseBody := maskBodyWithModel(c.Locals(responseModelKey), c.Response().Body())
+		requestBody = maskBodyWithModel(c.Locals(requestModelKey), requestBody)
 		headers := c.GetReqHeaders()
 
 		var fields []any
@@ -64,7 +153,7 @@ func (l *loggingMiddleware) Logging() fiber.Handler {
 				slog.String("ip", c.IP()),
 				slog.String("duration", elapse.String()),
 				slog.String("accept-language", convertHeaderAttrToString("Accept-Language", headers)),
-				slog.String("x-request-id", convertHeaderAttrToString("X-Request-Id", headers)),
+				slog.String("x-request-id", requestID),
 				slog.String("x-username", convertHeaderAttrToString("X-Username", headers)),
 				slog.String("x-user-id", convertHeaderAttrToString("X-User-Id", headers)),
 				slog.String("x-permissions", fmt.Sprint(headers["X-Permissions"])),
@@ -78,7 +167,11 @@ func (l *loggingMiddleware) Logging() fiber.Handler {
 			level = logger.Info
 		}
 
-		logger.CanonicalLogger(
+		canonicalLogger := logger.CanonicalLogger
+		if l.owner != nil {
+			canonicalLogger = l.owner.CanonicalLogger
+		}
+		canonicalLogger(
 			c.UserContext(),
 			l.logger,
 			level,
@@ -92,6 +185,9 @@ func (l *loggingMiddleware) Logging() fiber.Handler {
 				Status:    c.Response().StatusCode(),
 				Path:      c.Path(),
 				Duration:  elapse,
+
+				RequestContentType:  c.Get(fiber.HeaderContentType),
+				ResponseContentType: string(c.Response().Header.ContentType()),
 			},
 			fields,
 		)
@@ -0,0 +1,296 @@
+package nethttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func convertHeaderAttrToString(key string, headers http.Header) string {
+	if header, ok := headers[key]; ok && len(header) > 0 {
+		return header[0]
+	}
+	return ""
+}
+
+type LoggingMiddleware interface {
+	Logging() func(http.Handler) http.Handler
+}
+
+type loggingMiddleware struct {
+	logger slog.Logger
+	// owner is nil for the default Logger.
+	owner *logger.Logger
+}
+
+func NewLoggingMiddleware(slogger slog.Logger) LoggingMiddleware {
+	loggerWithName := slogger.With(slog.String("logger_name", "http_middleware"))
+	return &loggingMiddleware{
+		logger: *loggerWithName,
+	}
+}
+
+// NewLoggingMiddlewareWithLogger writes the access log of each net/http
+// request to l rather than the default Logger, and stores l's slog logger in
+// r.Context() for the wrapped handler.
+func NewLoggingMiddlewareWithLogger(l *logger.Logger) LoggingMiddleware {
+	middleware := NewLoggingMiddleware(*l.Slog()).(*loggingMiddleware)
+	middleware.owner = l
+	return middleware
+}
+
+func (l *loggingMiddleware) Logging() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startTime := time.Now()
+
+			owner := l.owner
+			if owner == nil {
+				owner = logger.Default()
+			}
+			bodyConfig := owner.BodyLogConfig()
+
+			// Only what the canonical log can show is kept in memory; the handler
+			// still reads the whole body.
+			var request *capturedRequest
+			if r.Body != nil && r.Body != http.NoBody {
+				request = captureRequest(r, bodyConfig)
+				r.Body = request
+			}
+
+			ctx, span := owner.StartServerSpan(r.Context(), r.Method, propagation.HeaderCarrier(r.Header),
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			)
+			defer span.End()
+			r = r.WithContext(ctx)
+
+			// The request ID is generated when the caller sent none, and returned
+			// so the caller can quote it. Handlers logging with r.Context() get
+			// the request and user IDs.
+			requestID := logger.RequestIDOrNew(r.Header.Get(logger.RequestIDHeader))
+			w.Header().Set(logger.RequestIDHeader, requestID)
+			r = r.WithContext(logger.RequestContext(r.Context(), l.owner, requestID, r.Header.Get("X-User-Id")))
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK, limit: bodyConfig.MaxResponseBytes, skip: bodyConfig.ShouldSkip}
+
+			// The canonical entry is written once the handler returns or panics. A
+			// panic is logged with its stack, answered with a 500 and makes the
+			// canonical entry an error that sampling keeps, except
+			// http.ErrAbortHandler, which net/http expects to see to abort the
+			// response.
+			defer func() {
+				var panicErr error
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					l.logger.ErrorContext(r.Context(), "recovered from panic",
+						slog.Any("panic", rec),
+						slog.String("stack", string(debug.Stack())),
+					)
+					if !rw.wroteHeader {
+						rw.Header().Set("Content-Type", "application/json")
+						rw.WriteHeader(http.StatusInternalServerError)
+						rw.Write([]byte(`{"error":"Internal Server Error"}`))
+					}
+					// The request failed even when the handler had already sent
+					// another status.
+					rw.statusCode = http.StatusInternalServerError
+					panicErr = fmt.Errorf("panic: %v", rec)
+				}
+
+				elapse := time.Since(startTime)
+				span.SetAttributes(attribute.Int("http.response.status_code", rw.statusCode))
+				if rw.statusCode >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(rw.statusCode))
+				}
+				headers := r.Header
+
+				var fields []any
+				fields = append(fields,
+					slog.String("logger_name", "canonical"),
+					slog.Group("httpserver_md",
+						slog.String("type", "httpserver"),
+						slog.String("method", r.Method),
+						slog.String("path", r.URL.Path),
+						slog.String("ip", clientIP(r)),
+						slog.String("duration", elapse.String()),
+						slog.String("accept-language", convertHeaderAttrToString("Accept-Language", headers)),
+						slog.String("x-request-id", requestID),
+						slog.String("x-username", convertHeaderAttrToString("X-Username", headers)),
+						slog.String("x-user-id", convertHeaderAttrToString("X-User-Id", headers)),
+						slog.String("x-permissions", fmt.Sprint(headers["X-Permissions"])),
+					),
+				)
+
+				var level logger.Level
+				if rw.statusCode >= http.StatusBadRequest {
+					level = logger.Error
+				} else {
+					level = logger.Info
+				}
+
+				canonicalLogger := logger.CanonicalLogger
+				if l.owner != nil {
+					canonicalLogger = l.owner.CanonicalLogger
+				}
+				canonicalLogger(
+					r.Context(),
+					l.logger,
+					level,
+					request.bytes(),
+					rw.body.Bytes(),
+					panicErr,
+					logger.CanonicalLog{
+						Transport: "http",
+						Traffic:   "internal",
+						Method:    r.Method,
+						Status:    rw.statusCode,
+						Path:      r.URL.Path,
+						Duration:  elapse,
+
+						RequestContentType:  r.Header.Get("Content-Type"),
+						ResponseContentType: rw.Header().Get("Content-Type"),
+						RequestSize:         request.size(r.ContentLength),
+						ResponseSize:        rw.size,
+					},
+					fields,
+				)
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// capturedRequest replays the first bytes of a request body, read up front up
+// to one past the request limit so the canonical log can tell the body was cut,
+// and counts the bytes the handler reads. Skipped content types are only
+// counted.
+type capturedRequest struct {
+	io.Reader
+	closer io.Closer
+	prefix []byte
+	read   int
+}
+
+func captureRequest(r *http.Request, config logger.BodyLogConfig) *capturedRequest {
+	request := &capturedRequest{Reader: r.Body, closer: r.Body}
+	switch {
+	case config.ShouldSkip(r.Header.Get("Content-Type")):
+	case config.MaxRequestBytes <= 0:
+		request.prefix, _ = io.ReadAll(r.Body)
+		request.Reader = bytes.NewReader(request.prefix)
+	default:
+		request.prefix, _ = io.ReadAll(io.LimitReader(r.Body, int64(config.MaxRequestBytes+1)))
+		request.Reader = io.MultiReader(bytes.NewReader(request.prefix), r.Body)
+	}
+	return request
+}
+
+func (c *capturedRequest) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.read += n
+	return n, err
+}
+
+func (c *capturedRequest) Close() error {
+	return c.closer.Close()
+}
+
+// bytes returns the captured prefix; nil for a skipped content type.
+func (c *capturedRequest) bytes() []byte {
+	if c == nil {
+		return nil
+	}
+	return c.prefix
+}
+
+// size is the body length for the canonical log: Content-Length when the
+// client sent one, otherwise what was read of it.
+func (c *capturedRequest) size(contentLength int64) int {
+	if c == nil {
+		return 0
+	}
+	if contentLength >= 0 {
+		return int(contentLength)
+	}
+	return max(c.read, len(c.prefix))
+}
+
+// responseWriter records the status code, the size of the body written by the
+// wrapped handler and its first bytes, up to one past limit (all of it when
+// limit is zero), so they can be included in the canonical log. Bodies of
+// content types the canonical log skips are only counted.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	size        int
+	limit       int
+	skip        func(contentType string) bool
+	skipped     bool
+}
+
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.statusCode = statusCode
+	rw.wroteHeader = true
+	rw.skipped = rw.skip != nil && rw.skip(rw.Header().Get("Content-Type"))
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.size += len(b)
+	if !rw.skipped {
+		keep := len(b)
+		if rw.limit > 0 {
+			keep = min(keep, rw.limit+1-rw.body.Len())
+		}
+		if keep > 0 {
+			rw.body.Write(b[:keep])
+		}
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func HTTPMiddleware() func(http.Handler) http.Handler {
+	if logger.Slog == nil {
+		panic("Logger not initialized. Call logger.Init() first.")
+	}
+	return NewLoggingMiddleware(*logger.Slog).Logging()
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks the first write until release is called, so tests can fill
+// the async buffer while the writer goroutine is busy.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.entered)
+		<-w.gate
+	})
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) release() {
+	close(w.gate)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func newAsyncLogger(t *testing.T, w *gatedWriter, async logger.AsyncConfig) *logger.Logger {
+	async.Enabled = true
+	instance := logger.New(
+		logger.WithConfig(logger.Config{Env: "test", ServiceName: "async-test", Level: "debug", UseJSON: true, Async: async}),
+		logger.WithWriter(w),
+	)
+	t.Cleanup(func() { _ = instance.Close() })
+	return instance
+}
+
+// fillAsyncBuffer logs "first" and waits until the writer goroutine is stuck
+// writing it, then logs count more entries, which all stay queued.
+func fillAsyncBuffer(t *testing.T, instance *logger.Logger, w *gatedWriter, count int) {
+	instance.Slog().InfoContext(context.Background(), "first")
+	select {
+	case <-w.entered:
+	case <-time.After(time.Second):
+		t.Fatal("writer goroutine did not pick up the first entry")
+	}
+	for i := 0; i < count; i++ {
+		instance.Slog().InfoContext(context.Background(), fmt.Sprintf("queued %d", i))
+	}
+}
+
+func messages(t *testing.T, output string) []string {
+	var msgs []string
+	for _, entry := range decodeLogLines(t, output) {
+		msgs = append(msgs, entry["msg"].(string))
+	}
+	return msgs
+}
+
+func TestAsync_WritesEverythingOnShutdown(t *testing.T) {
+	w := newGatedWriter()
+	w.release()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 16})
+
+	for i := 0; i < 100; i++ {
+		instance.Slog().InfoContext(context.Background(), "entry")
+	}
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Len(t, messages(t, w.String()), 100)
+	stats := instance.AsyncStats()
+	assert.Equal(t, uint64(100), stats.Written)
+	assert.Equal(t, 0, stats.QueueDepth)
+	assert.Equal(t, 16, stats.Capacity)
+}
+
+func TestAsync_DropNewest(t *testing.T) {
+	w := newGatedWriter()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 2, Overflow: logger.OverflowDropNewest})
+
+	fillAsyncBuffer(t, instance, w, 5)
+	stats := instance.AsyncStats()
+	assert.Equal(t, 2, stats.QueueDepth)
+	assert.Equal(t, uint64(3), stats.Dropped)
+
+	w.release()
+	require.NoError(t, instance.Shutdown(context.Background()))
+	assert.Equal(t, []string{"first", "queued 0", "queued 1"}, messages(t, w.String()))
+}
+
+func TestAsync_DropOldest(t *testing.T) {
+	w := newGatedWriter()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 2, Overflow: logger.OverflowDropOldest})
+
+	fillAsyncBuffer(t, instance, w, 5)
+	assert.Equal(t, uint64(3), instance.AsyncStats().Dropped)
+
+	w.release()
+	require.NoError(t, instance.Shutdown(context.Background()))
+	assert.Equal(t, []string{"first", "queued 3", "queued 4"}, messages(t, w.String()))
+}
+
+func TestAsync_DropBelowLevel(t *testing.T) {
+	w := newGatedWriter()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 2, Overflow: logger.OverflowDropBelowLevel, DropLevel: "warn"})
+
+	fillAsyncBuffer(t, instance, w, 2)
+	instance.Slog().InfoContext(context.Background(), "dropped info")
+	assert.Equal(t, uint64(1), instance.AsyncStats().Dropped)
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		instance.Slog().ErrorContext(context.Background(), "kept error")
+	}()
+	assert.Eventually(t, func() bool { return instance.AsyncStats().Blocked == 1 }, time.Second, time.Millisecond)
+
+	w.release()
+	<-logged
+	require.NoError(t, instance.Shutdown(context.Background()))
+	assert.Equal(t, []string{"first", "queued 0", "queued 1", "kept error"}, messages(t, w.String()))
+}
+
+func TestAsync_BlockWaitsForRoom(t *testing.T) {
+	w := newGatedWriter()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 1, Overflow: logger.OverflowBlock})
+
+	fillAsyncBuffer(t, instance, w, 1)
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		instance.Slog().InfoContext(context.Background(), "waited")
+	}()
+	assert.Eventually(t, func() bool { return instance.AsyncStats().Blocked == 1 }, time.Second, time.Millisecond)
+
+	w.release()
+	<-logged
+	require.NoError(t, instance.Shutdown(context.Background()))
+	assert.Equal(t, []string{"first", "queued 0", "waited"}, messages(t, w.String()))
+	assert.Zero(t, instance.AsyncStats().Dropped)
+}
+
+func TestAsync_Validate(t *testing.T) {
+	err := logger.Config{Async: logger.AsyncConfig{BufferSize: -1, Overflow: "spill", DropLevel: "loud", FlushInterval: -time.Second}}.Validate()
+	assert.Equal(t, []string{"async.bufferSize", "async.dropLevel", "async.flushInterval", "async.overflow"}, validationFields(t, err))
+}
+
+func TestAsync_ReconfigureRejectsChange(t *testing.T) {
+	w := newGatedWriter()
+	w.release()
+	instance := newAsyncLogger(t, w, logger.AsyncConfig{BufferSize: 8})
+
+	config := instance.Config()
+	config.Async.BufferSize = 64
+	rejected, err := instance.Reconfigure(config)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "async", rejected[0].Field)
+	assert.Equal(t, 8, instance.AsyncStats().Capacity)
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logCanonicalBody(t *testing.T, body logger.BodyLogConfig, canonicalLog logger.CanonicalLog, request, response []byte) map[string]interface{} {
+	logger.Init(logger.Config{Env: "test", ServiceName: "body-test", Level: "debug", UseJSON: true, Body: body})
+	t.Cleanup(func() { logger.SetBodyLogConfig(logger.BodyLogConfig{}) })
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.CanonicalLogger(context.Background(), *slogger, logger.Info, request, response, nil, canonicalLog, []any{})
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	return entry
+}
+
+func TestCanonicalLogger_TruncatesLargeBodies(t *testing.T) {
+	items := make([]string, 50)
+	for i := range items {
+		items[i] = `"item"`
+	}
+	response := []byte(`{"items":[` + strings.Join(items, ",") + `]}`)
+	request := []byte(`{"q":"small"}`)
+
+	entry := logCanonicalBody(t,
+		logger.BodyLogConfig{MaxRequestBytes: 1024, MaxResponseBytes: 32},
+		logger.CanonicalLog{Transport: "http", Method: "GET", Status: 200, Path: "/api/items"},
+		request, response,
+	)
+
+	assert.Equal(t, map[string]interface{}{"q": "small"}, entry["request"])
+	assert.NotContains(t, entry, "request_truncated")
+
+	assert.True(t, strings.HasSuffix(entry["response"].(string), "...[truncated]"))
+	assert.Equal(t, `{"items":["item","item","item","`, strings.TrimSuffix(entry["response"].(string), "...[truncated]"))
+	assert.Equal(t, float64(len(response)), entry["response_size"])
+	assert.Equal(t, true, entry["response_truncated"])
+}
+
+func TestCanonicalLogger_RedactsBeforeTruncating(t *testing.T) {
+	request := []byte(`{"password":"super-secret-value","padding":"` + strings.Repeat("x", 100) + `"}`)
+
+	entry := logCanonicalBody(t,
+		logger.BodyLogConfig{MaxRequestBytes: 40},
+		logger.CanonicalLog{Transport: "http", Method: "POST", Status: 200, Path: "/api/users"},
+		request, []byte(`{}`),
+	)
+
+	assert.Equal(t, true, entry["request_truncated"])
+	assert.NotContains(t, entry["request"], "super-secret-value")
+}
+
+func TestCanonicalLogger_SkipsBinaryContentTypes(t *testing.T) {
+	entry := logCanonicalBody(t,
+		logger.BodyLogConfig{},
+		logger.CanonicalLog{
+			Transport:           "http",
+			Method:              "POST",
+			Status:              200,
+			Path:                "/api/upload",
+			RequestContentType:  "multipart/form-data; boundary=abc",
+			ResponseContentType: "application/json",
+		},
+		[]byte("--abc\r\nContent-Disposition: form-data; name=\"file\"\r\n\r\nfile-bytes\r\n--abc--"),
+		[]byte(`{"uploaded":true}`),
+	)
+
+	assert.Equal(t, "[skipped multipart/form-data; boundary=abc body]", entry["request"])
+	assert.NotNil(t, entry["request_size"])
+	assert.Equal(t, map[string]interface{}{"uploaded": true}, entry["response"])
+}
+
+func TestCanonicalLogger_SkipsNonUTF8Bodies(t *testing.T) {
+	entry := logCanonicalBody(t,
+		logger.BodyLogConfig{SkipContentTypes: []string{}},
+		logger.CanonicalLog{Transport: "http", Method: "GET", Status: 200, Path: "/api/avatar", ResponseContentType: "image/png"},
+		nil,
+		[]byte{0x89, 0x50, 0x4e, 0x47, 0xff, 0xfe},
+	)
+
+	assert.Equal(t, "[skipped image/png body]", entry["response"])
+	assert.Equal(t, float64(6), entry["response_size"])
+}
+
+func TestCanonicalLogger_CustomSkipContentTypes(t *testing.T) {
+	entry := logCanonicalBody(t,
+		logger.BodyLogConfig{SkipContentTypes: []string{"text/csv"}},
+		logger.CanonicalLog{Transport: "http", Method: "GET", Status: 200, Path: "/api/export", ResponseContentType: "text/csv; charset=utf-8"},
+		nil,
+		[]byte("id,name\n1,John"),
+	)
+
+	assert.Equal(t, "[skipped text/csv; charset=utf-8 body]", entry["response"])
+}
+
+func TestLoadFromFile_BodyLimits(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "config-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`log:
+  env: production
+  serviceName: upload-service
+  body:
+    maxRequestBytes: 2048
+    maxResponseBytes: 4096
+    skipContentTypes:
+      - multipart/
+      - application/octet-stream`)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	got, err := config.LoadFromFile(tmpFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, logger.BodyLogConfig{
+		MaxRequestBytes:  2048,
+		MaxResponseBytes: 4096,
+		SkipContentTypes: []string{"multipart/", "application/octet-stream"},
+	}, got.Body)
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentLevels_RoutesOnLoggerName(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "component-test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{
+			"canonical":     "warn",
+			"svc.payment.*": "debug",
+			"svc.*":         "error",
+		},
+	})
+
+	ctx := context.Background()
+	payment := logger.NewPathfinder("payment")
+	refund := payment.NewPathfinder("refund")
+	order := logger.NewPathfinder("order")
+
+	payment.DebugContext(ctx, "payment debug")
+	refund.DebugContext(ctx, "refund debug")
+	order.InfoContext(ctx, "order info")
+	order.ErrorContext(ctx, "order error")
+	logger.Slog.InfoContext(ctx, "canonical info", slog.String("logger_name", "canonical"))
+	logger.Slog.WarnContext(ctx, "canonical warn", slog.String("logger_name", "canonical"))
+	logger.Slog.DebugContext(ctx, "root debug")
+	logger.Slog.InfoContext(ctx, "root info")
+	_ = logger.Log.Sync()
+
+	assert.Equal(t, 1, countMessages(t, file, "[service][payment] payment debug"))
+	assert.Equal(t, 1, countMessages(t, file, "[service][payment.refund] refund debug"))
+	assert.Equal(t, 0, countMessages(t, file, "[service][order] order info"))
+	assert.Equal(t, 1, countMessages(t, file, "[service][order] order error"))
+	assert.Equal(t, 0, countMessages(t, file, "canonical info"))
+	assert.Equal(t, 1, countMessages(t, file, "canonical warn"))
+	assert.Equal(t, 0, countMessages(t, file, "root debug"))
+	assert.Equal(t, 1, countMessages(t, file, "root info"))
+}
+
+func TestComponentLevels_HandlerEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger.Init(logger.Config{
+		Env: "test", ServiceName: "component-test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{"http_middleware": "debug", "grpc_interceptor": "error"},
+	})
+	defer logger.Init(logger.Config{Env: "test", ServiceName: "component-test", Level: "info", UseJSON: true})
+
+	handler := logger.NewHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := context.Background()
+
+	httpLogger := slog.New(handler).With(slog.String("logger_name", "http_middleware"))
+	grpcLogger := slog.New(handler).With(slog.String("logger_name", "grpc_interceptor"))
+	assert.True(t, httpLogger.Enabled(ctx, slog.LevelDebug))
+	assert.False(t, grpcLogger.Enabled(ctx, slog.LevelWarn))
+	assert.True(t, grpcLogger.Enabled(ctx, slog.LevelError))
+
+	grpcLogger.WarnContext(ctx, "suppressed")
+	slog.New(handler).WarnContext(ctx, "per record", slog.String("logger_name", "grpc_interceptor"))
+	assert.Empty(t, buf.String())
+}
+
+func TestComponentLevels_LastLoggerNameWins(t *testing.T) {
+	instance, buf := newBufferedLogger(t, logger.WithConfig(logger.Config{
+		Env: "test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{"quiet": "error", "loud": "debug"},
+	}))
+	ctx := context.Background()
+
+	instance.Slog().DebugContext(ctx, "renamed to loud", slog.String("logger_name", "quiet"), slog.String("logger_name", "loud"))
+	instance.Slog().With(slog.String("logger_name", "loud")).WarnContext(ctx, "renamed to quiet", slog.String("logger_name", "quiet"))
+
+	assert.Contains(t, buf.String(), "renamed to loud")
+	assert.NotContains(t, buf.String(), "renamed to quiet")
+}
+
+func TestComponentLevels_RuntimeOverrideWins(t *testing.T) {
+	logger.Init(logger.Config{
+		Env: "test", ServiceName: "component-test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{"svc.payment.*": "debug"},
+	})
+	defer logger.Init(logger.Config{Env: "test", ServiceName: "component-test", Level: "info", UseJSON: true})
+
+	assert.Equal(t, "debug", logger.GetLoggerLevel("svc.payment.refund"))
+	require.NoError(t, logger.SetLoggerLevel("svc.payment.refund", "error"))
+	assert.Equal(t, "error", logger.GetLoggerLevel("svc.payment.refund"))
+	assert.Equal(t, "debug", logger.GetLoggerLevel("svc.payment"))
+	assert.Equal(t, "info", logger.GetLoggerLevel("svc.order"))
+}
+
+func TestLoadFromFile_ComponentLevels(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "config-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`log:
+  env: production
+  level: info
+  componentLevels:
+    canonical: warn
+    svc.payment.*: debug`)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	got, err := config.LoadFromFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"canonical": "warn", "svc.payment.*": "debug"}, got.ComponentLevels)
+}
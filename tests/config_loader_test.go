@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNamedConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad_LayersDefaultsFileEnvAndOptions(t *testing.T) {
+	path := writeNamedConfig(t, "config.yaml", `log:
+  env: production
+  level: warn
+  sampling:
+    enabled: true`)
+	t.Setenv("BLOGGER_FILE_SIZE", "250")
+
+	effective, err := config.LoadEffective(config.WithFile(path), config.WithServiceName("layered-service"))
+	require.NoError(t, err)
+
+	got := effective.Config
+	assert.Equal(t, "production", got.Env)
+	assert.Equal(t, "warn", got.Level)
+	assert.True(t, got.UseJSON, "production defaults apply when the file sets env")
+	assert.Equal(t, 250, got.FileSize)
+	assert.Equal(t, 30, got.MaxAge)
+	assert.Equal(t, "layered-service", got.ServiceName)
+	assert.True(t, got.Sampling.Enabled)
+
+	assert.Equal(t, "file:"+path, effective.Sources["env"])
+	assert.Equal(t, "file:"+path, effective.Sources["level"])
+	assert.Equal(t, "file:"+path, effective.Sources["sampling.enabled"])
+	assert.Equal(t, config.SourceDefault, effective.Sources["sampling.tick"])
+	assert.Equal(t, config.SourceDefault, effective.Sources["useJsonEncoder"])
+	assert.Equal(t, "env:BLOGGER_FILE_SIZE", effective.Sources["fileSize"])
+	assert.Equal(t, config.SourceOption, effective.Sources["serviceName"])
+
+	printed := effective.String()
+	assert.Regexp(t, `level\s+"warn"\s+\(file:.*config\.yaml\)`, printed)
+	assert.Regexp(t, `serviceName\s+"layered-service"\s+\(option\)`, printed)
+	assert.Regexp(t, `fileSize\s+250\s+\(env:BLOGGER_FILE_SIZE\)`, printed)
+}
+
+func TestLoad_WithoutFileUsesEnvDefaults(t *testing.T) {
+	t.Setenv("BLOGGER_ENV", "development")
+
+	got, err := config.Load(config.WithLevel("info"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "development", got.Env)
+	assert.Equal(t, "info", got.Level)
+	assert.False(t, got.UseJSON)
+	assert.Equal(t, "logs/app.log", got.FilePath)
+
+	// The loaded struct is what logger.Init consumes.
+	slogger := logger.Init(*got)
+	assert.NotNil(t, slogger)
+}
+
+func TestLoad_JSONAndTOMLMatchYAML(t *testing.T) {
+	yamlPath := writeNamedConfig(t, "config.yaml", `log:
+  env: production
+  serviceName: format-service
+  level: error
+  componentLevels:
+    canonical: warn
+  sampling:
+    enabled: true
+    tick: 2s
+    levels:
+      debug: 0.5`)
+	jsonPath := writeNamedConfig(t, "config.json", `{
+	"log": {
+		"env": "production",
+		"serviceName": "format-service",
+		"level": "error",
+		"componentLevels": {"canonical": "warn"},
+		"sampling": {"enabled": true, "tick": "2s", "levels": {"debug": 0.5}}
+	}
+}`)
+	tomlPath := writeNamedConfig(t, "config.toml", `[log]
+env = "production"
+serviceName = "format-service"
+level = "error"
+
+[log.componentLevels]
+canonical = "warn"
+
+[log.sampling]
+enabled = true
+tick = "2s"
+
+[log.sampling.levels]
+debug = 0.5
+`)
+
+	fromYAML, err := config.Load(config.WithFile(yamlPath))
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, fromYAML.Sampling.Tick)
+
+	for _, path := range []string{jsonPath, tomlPath} {
+		got, err := config.Load(config.WithFile(path), config.WithStrict())
+		require.NoError(t, err, path)
+		assert.Equal(t, fromYAML, got, path)
+	}
+}
+
+func TestLoad_StrictRejectsUnknownTOMLKeys(t *testing.T) {
+	path := writeNamedConfig(t, "config.toml", `[log]
+env = "production"
+levl = "debug"
+`)
+
+	_, err := config.Load(config.WithFile(path), config.WithStrict())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "levl")
+}
+
+func TestLoadFromFile_AppliesAllEnvOverrides(t *testing.T) {
+	path := writeNamedConfig(t, "config.yaml", `log:
+  env: production
+  serviceName: file-service`)
+	t.Setenv("BLOGGER_FILE_SIZE", "64")
+	t.Setenv("BLOGGER_MAX_AGE", "7")
+	t.Setenv("BLOGGER_MAX_BACKUPS", "9")
+
+	got, err := config.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 64, got.FileSize)
+	assert.Equal(t, 7, got.MaxAge)
+	assert.Equal(t, 9, got.MaxBackups)
+}
+
+func TestEffectiveString_MasksOutputHeaders(t *testing.T) {
+	path := writeNamedConfig(t, "config.yaml", `log:
+  outputs:
+    - type: loki
+      address: http://loki:3100
+      headers:
+        Authorization: Bearer secret-token`)
+
+	effective, err := config.LoadEffective(config.WithFile(path))
+	require.NoError(t, err)
+	require.Equal(t, "Bearer secret-token", effective.Config.Outputs[0].Headers["Authorization"])
+
+	printed := effective.String()
+	assert.NotContains(t, printed, "secret-token")
+	assert.Contains(t, printed, `"Authorization":"*****"`)
+	assert.Contains(t, printed, "http://loki:3100")
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func validationFields(t *testing.T, err error) []string {
+	var validationErrs logger.ValidationErrors
+	require.True(t, errors.As(err, &validationErrs), "expected ValidationErrors, got %v", err)
+
+	fields := make([]string, len(validationErrs))
+	for i, validationErr := range validationErrs {
+		fields[i] = validationErr.Field
+	}
+	return fields
+}
+
+func TestLogConfig_Validate(t *testing.T) {
+	valid := config.GetDefault("production")
+	assert.NoError(t, valid.Validate())
+
+	invalid := &config.LogConfig{
+		Level:       "verbose",
+		FileEnabled: true,
+		FileSize:    -1,
+		MaxBackups:  -3,
+		Body:        logger.BodyLogConfig{MaxResponseBytes: -10},
+		Sampling: logger.SamplingConfig{
+			Levels: map[string]float64{"debug": 1.5},
+			Paths:  []logger.PathSamplingRule{{Pattern: "/health", Rate: -0.1}},
+		},
+		ComponentLevels: map[string]string{"svc.[": "loud"},
+		Redaction: &logger.RedactionConfig{
+			FieldRules: []logger.FieldRule{{Path: "password", Strategy: "shred"}},
+			ValueRules: []logger.ValueRule{{Name: "bad", Pattern: "(", Strategy: logger.RedactMask}},
+		},
+	}
+
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"body.maxResponseBytes",
+		"componentLevels[svc.[]",
+		"componentLevels[svc.[]",
+		"filePath",
+		"fileSize",
+		"level",
+		"maxBackups",
+		"redaction.fieldRules[0].strategy",
+		"redaction.valueRules[0].pattern",
+		"sampling.levels.debug",
+		"sampling.paths[0].rate",
+	}, validationFields(t, err))
+	assert.Contains(t, err.Error(), `level: unknown level "verbose"`)
+}
+
+func TestLoadFromFile_StrictRejectsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, `log:
+  env: production
+  level: loud
+  serviceNmae: typo-service
+  fileSize: -5`)
+
+	lenient, err := config.LoadFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "loud", lenient.Level)
+
+	_, err = config.LoadFromFile(path, config.WithStrict())
+	require.Error(t, err)
+
+	var typeErr *yaml.TypeError
+	require.True(t, errors.As(err, &typeErr))
+	assert.Contains(t, typeErr.Error(), "serviceNmae")
+	assert.Equal(t, []string{"fileSize", "level"}, validationFields(t, err))
+}
+
+func TestLoadFromFile_StrictAcceptsValidConfig(t *testing.T) {
+	path := writeConfigFile(t, `log:
+  env: production
+  serviceName: strict-service
+  level: warn
+  sampling:
+    enabled: true
+    levels:
+      debug: 0.5`)
+
+	got, err := config.LoadFromFile(path, config.WithStrict())
+	require.NoError(t, err)
+	assert.Equal(t, "warn", got.Level)
+}
+
+func TestLoadFromEnvWithOptions_StrictRejectsGarbage(t *testing.T) {
+	t.Setenv("BLOGGER_ENV", "production")
+	t.Setenv("BLOGGER_FILE_SIZE", "100MB")
+	t.Setenv("BLOGGER_USE_JSON", "yes please")
+	t.Setenv("BLOGGER_LOG_LEVEL", "chatty")
+
+	lenient, err := config.LoadFromEnvWithOptions()
+	require.NoError(t, err)
+	assert.Equal(t, 100, lenient.FileSize)
+
+	_, err = config.LoadFromEnvWithOptions(config.WithStrict())
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{"BLOGGER_USE_JSON", "BLOGGER_FILE_SIZE"}, validationFields(t, err))
+	assert.Contains(t, err.Error(), `unknown level "chatty"`)
+}
+
+func TestLoadFromEnvWithOptions_StrictMatchesLenientParsing(t *testing.T) {
+	t.Setenv("BLOGGER_ENV", "production")
+	t.Setenv("BLOGGER_USE_JSON", "0")
+	t.Setenv("BLOGGER_FILE_ENABLED", "T")
+
+	got, err := config.LoadFromEnvWithOptions(config.WithStrict())
+	require.NoError(t, err)
+	assert.False(t, got.UseJSON)
+	assert.True(t, got.FileEnabled)
+
+	t.Setenv("BLOGGER_FILE_SIZE", "0")
+	lenient, err := config.LoadFromEnvWithOptions()
+	require.NoError(t, err)
+	assert.Equal(t, config.GetDefault("production").FileSize, lenient.FileSize, "an ignored value keeps the default")
+
+	_, err = config.LoadFromEnvWithOptions(config.WithStrict())
+	require.Error(t, err)
+	assert.Equal(t, []string{"BLOGGER_FILE_SIZE"}, validationFields(t, err))
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/tracelog"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/grpcserver"
+	"github.com/pawatthir/blogger/middleware/httpserver"
+	"github.com/pawatthir/blogger/middleware/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// findMessage returns the entry logged with msg.
+func findMessage(t *testing.T, output, msg string) map[string]any {
+	for _, entry := range decodeLogLines(t, output) {
+		if entry["msg"] == msg {
+			return entry
+		}
+	}
+	t.Fatalf("no entry %q in %s", msg, output)
+	return nil
+}
+
+func TestContextAttrs_AddedToRecords(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	ctx = logger.WithUserID(ctx, "user-7")
+	ctx = logger.WithTenant(ctx, "acme")
+	ctx = logger.WithRoute(ctx, "/orders/:id")
+	ctx = logger.ContextWithAttrs(ctx, slog.Int("shard", 3))
+
+	instance.Slog().InfoContext(ctx, "with attrs")
+	instance.Slog().InfoContext(ctx, "explicit wins", "tenant", "override")
+	instance.Slog().InfoContext(context.Background(), "without attrs")
+
+	entry := findMessage(t, buf.String(), "with attrs")
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "user-7", entry["user_id"])
+	assert.Equal(t, "acme", entry["tenant"])
+	assert.Equal(t, "/orders/:id", entry["route"])
+	assert.Equal(t, float64(3), entry["shard"])
+
+	assert.Equal(t, "override", findMessage(t, buf.String(), "explicit wins")["tenant"])
+	assert.NotContains(t, findMessage(t, buf.String(), "without attrs"), "request_id")
+}
+
+func TestHandlerWithGroup_KeepsContextAttrsAndLevels(t *testing.T) {
+	instance, buf := newBufferedLogger(t, logger.WithConfig(logger.Config{
+		Env: "test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{"svc": "error"},
+	}))
+	ctx := logger.WithRequestID(context.Background(), "req-group")
+
+	grouped := instance.Slog().With(slog.String("logger_name", "svc")).WithGroup("order")
+	grouped.WarnContext(ctx, "grouped warn")
+	grouped.ErrorContext(ctx, "grouped error", slog.String("id", "o-1"))
+
+	assert.NotContains(t, buf.String(), "grouped warn")
+	entry := findMessage(t, buf.String(), "grouped error")
+	group, ok := entry["order"].(map[string]any)
+	require.True(t, ok, "order group missing in %v", entry)
+	assert.Equal(t, "o-1", group["id"])
+	assert.Equal(t, "req-group", group["request_id"])
+	assert.Contains(t, group, "dd")
+}
+
+func TestContextWithAttrs_ReplacesSameKey(t *testing.T) {
+	ctx := logger.WithRequestID(context.Background(), "first")
+	ctx = logger.WithUserID(ctx, "user-1")
+	replaced := logger.WithRequestID(ctx, "second")
+
+	assert.Equal(t, "first", logger.RequestIDFromContext(ctx))
+	assert.Equal(t, "second", logger.RequestIDFromContext(replaced))
+	assert.Len(t, logger.ContextAttrs(replaced), 2)
+	assert.Empty(t, logger.RequestIDFromContext(context.Background()))
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, slog.Default(), logger.FromContext(context.Background()))
+
+	instance, _ := newBufferedLogger(t)
+	ctx := logger.WithContext(context.Background(), instance.Slog())
+	assert.Same(t, instance.Slog(), logger.FromContext(ctx))
+}
+
+func TestPathfinderAndPGXLogger_UseContextLogger(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	ctx := logger.WithContext(logger.WithRequestID(context.Background(), "req-9"), instance.Slog())
+
+	logger.NewPathfinder("payment").InfoContext(ctx, "charged")
+	logger.NewPGXLoggerFromSlog().Log(ctx, tracelog.LogLevelInfo, "Query", map[string]interface{}{"sql": "select 1"})
+
+	charged := findMessage(t, buf.String(), "[service][payment] charged")
+	assert.Equal(t, "req-9", charged["request_id"])
+	assert.Equal(t, "svc.payment", charged["logger_name"])
+	assert.Equal(t, "req-9", findMessage(t, buf.String(), "Query")["request_id"])
+}
+
+func TestFiberMiddleware_AttachesRequestAttrs(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		ctx := logger.WithRoute(c.UserContext(), c.Route().Path)
+		logger.FromContext(ctx).InfoContext(ctx, "loading order")
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/orders/42", nil)
+	req.Header.Set("X-Request-Id", "req-fiber")
+	req.Header.Set("X-User-Id", "user-3")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	entry := findMessage(t, buf.String(), "loading order")
+	assert.Equal(t, "req-fiber", entry["request_id"])
+	assert.Equal(t, "user-3", entry["user_id"])
+	assert.Equal(t, "/orders/:id", entry["route"])
+}
+
+func TestNetHTTPMiddleware_AttachesRequestAttrs(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	handler := nethttp.NewLoggingMiddlewareWithLogger(instance).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).InfoContext(r.Context(), "handling")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("X-Request-Id", "req-http")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := findMessage(t, buf.String(), "handling")
+	assert.Equal(t, "req-http", entry["request_id"])
+	assert.NotContains(t, entry, "user_id")
+}
+
+func TestGRPCInterceptor_AttachesRoute(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	interceptor := grpcserver.NewUnaryLoggerInterceptorWithLogger(instance).Intercept()
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger.FromContext(ctx).InfoContext(ctx, "in handler")
+			return nil, nil
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/orders.v1.Orders/Get", findMessage(t, buf.String(), "in handler")["route"])
+}
+
+func TestRequestContext_AttachesIDsAndLogger(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	ctx := logger.RequestContext(context.Background(), instance, "req-1", "")
+
+	logger.FromContext(ctx).InfoContext(ctx, "handling")
+
+	entry := findMessage(t, buf.String(), "handling")
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.NotContains(t, entry, "user_id")
+}
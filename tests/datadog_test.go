@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// datadogIntake stands in for the logs intake. The first failures requests get
+// failStatus.
+type datadogIntake struct {
+	mu         sync.Mutex
+	requests   []*http.Request
+	batches    [][]map[string]any
+	failures   int
+	failStatus int
+}
+
+func (d *datadogIntake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, r)
+	if len(d.requests) <= d.failures {
+		w.WriteHeader(d.failStatus)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = reader
+	}
+	var logs []map[string]any
+	if err := json.NewDecoder(body).Decode(&logs); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	d.batches = append(d.batches, logs)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (d *datadogIntake) logs() []map[string]any {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var logs []map[string]any
+	for _, batch := range d.batches {
+		logs = append(logs, batch...)
+	}
+	return logs
+}
+
+func TestDatadogSink(t *testing.T) {
+	intake := &datadogIntake{}
+	instance := newSinkLogger(t,
+		logger.OutputConfig{Type: "datadog", Address: serveHTTP(t, intake) + "/api/v2/logs", APIKey: "key-123", Tags: []string{"team:payments"}},
+		logger.WithEnv("prod"), logger.WithServiceName("checkout"), logger.WithVersion("2.0.1"),
+	)
+
+	instance.Slog().ErrorContext(context.Background(), "charge failed", "order_id", "ord_9")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, intake.requests, 1)
+	request := intake.requests[0]
+	assert.Equal(t, "/api/v2/logs", request.URL.Path)
+	assert.Equal(t, "key-123", request.Header.Get("DD-API-KEY"))
+	assert.Equal(t, "gzip", request.Header.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+	logs := intake.logs()
+	require.Len(t, logs, 1)
+	entry := logs[0]
+	hostname, _ := os.Hostname()
+	assert.Equal(t, "charge failed", entry["message"])
+	assert.Equal(t, "error", entry["status"])
+	assert.Equal(t, "checkout", entry["service"])
+	assert.Equal(t, "go", entry["ddsource"])
+	assert.Equal(t, "env:prod,version:2.0.1,team:payments", entry["ddtags"])
+	assert.Equal(t, hostname, entry["hostname"])
+	assert.Equal(t, "ord_9", entry["order_id"])
+	assert.NotZero(t, entry["timestamp"])
+	assert.NotContains(t, entry, "msg")
+	assert.Contains(t, entry, "dd")
+}
+
+func TestDatadogSink_APIKeyFromEnv(t *testing.T) {
+	t.Setenv("DD_API_KEY", "from-env")
+	intake := &datadogIntake{}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "datadog", Address: serveHTTP(t, intake) + "/api/v2/logs", Compression: "none", Source: "billing"})
+
+	instance.Slog().InfoContext(context.Background(), "plain")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, intake.requests, 1)
+	assert.Equal(t, "from-env", intake.requests[0].Header.Get("DD-API-KEY"))
+	assert.Empty(t, intake.requests[0].Header.Get("Content-Encoding"))
+	assert.Equal(t, "billing", intake.logs()[0]["ddsource"])
+}
+
+func TestDatadogSink_RetriesServerErrors(t *testing.T) {
+	intake := &datadogIntake{failures: 2, failStatus: http.StatusInternalServerError}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "datadog", Address: serveHTTP(t, intake) + "/api/v2/logs"})
+
+	instance.Slog().InfoContext(context.Background(), "retried")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Len(t, intake.requests, 3)
+	assert.Len(t, intake.logs(), 1)
+}
+
+func TestDatadogSink_DoesNotRetryForbidden(t *testing.T) {
+	intake := &datadogIntake{failures: 1, failStatus: http.StatusForbidden}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "datadog", Address: serveHTTP(t, intake) + "/api/v2/logs"})
+
+	instance.Slog().InfoContext(context.Background(), "bad key")
+	assert.Error(t, instance.Shutdown(context.Background()))
+	assert.Len(t, intake.requests, 1)
+}
+
+func TestDatadogSink_BatchesBySizeAndBytes(t *testing.T) {
+	intake := &datadogIntake{}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "datadog", Address: serveHTTP(t, intake) + "/api/v2/logs", BatchSize: 3, BatchBytes: 600, BatchInterval: time.Hour})
+
+	for i := 0; i < 4; i++ {
+		instance.Slog().InfoContext(context.Background(), "sized", "i", i)
+	}
+	instance.Slog().InfoContext(context.Background(), "large", "payload", string(make([]byte, 700)))
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Len(t, intake.logs(), 5)
+	for _, batch := range intake.batches {
+		assert.LessOrEqual(t, len(batch), 3)
+		if len(batch) > 1 {
+			for _, entry := range batch {
+				assert.NotEqual(t, "large", entry["message"], "an entry over BatchBytes is sent on its own")
+			}
+		}
+	}
+}
+
+func TestDatadogSink_ValidateCompression(t *testing.T) {
+	err := logger.Config{Outputs: []logger.OutputConfig{{Type: "datadog", Compression: "zstd"}}}.Validate()
+	assert.Equal(t, []string{"outputs[0].compression"}, validationFields(t, err))
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkServer answers _bulk requests; reject decides the status of each
+// document on each call, 201 when nil.
+type bulkServer struct {
+	mu        sync.Mutex
+	calls     int
+	auth      string
+	indices   []string
+	documents []map[string]any
+	reject    func(call int, document map[string]any) int
+}
+
+func (b *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	b.auth = r.Header.Get("Authorization")
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var items []string
+	hasErrors := false
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action map[string]map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var document map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		status := http.StatusCreated
+		if b.reject != nil {
+			status = b.reject(b.calls, document)
+		}
+		if status == http.StatusCreated {
+			b.indices = append(b.indices, action["create"]["_index"])
+			b.documents = append(b.documents, document)
+			items = append(items, `{"create":{"status":201}}`)
+		} else {
+			hasErrors = true
+			items = append(items, fmt.Sprintf(`{"create":{"status":%d,"error":{"type":"rejected","reason":"test"}}}`, status))
+		}
+	}
+	fmt.Fprintf(w, `{"errors":%t,"items":[%s]}`, hasErrors, strings.Join(items, ","))
+}
+
+func TestElasticsearchSink_DailyIndices(t *testing.T) {
+	bulk := &bulkServer{}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "elasticsearch", Address: serveHTTP(t, bulk), APIKey: "abc"}, logger.WithServiceName("Orders"))
+
+	instance.Slog().InfoContext(context.Background(), "indexed", "order_id", "ord_3")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Equal(t, "ApiKey abc", bulk.auth)
+	require.Len(t, bulk.documents, 1)
+	assert.Equal(t, "orders-"+time.Now().UTC().Format("2006.01.02"), bulk.indices[0])
+
+	document := bulk.documents[0]
+	assert.Equal(t, "indexed", document["message"])
+	assert.Equal(t, "info", document["level"])
+	assert.Equal(t, "ord_3", document["order_id"])
+	assert.NotContains(t, document, "msg")
+	assert.NotContains(t, document, "ts")
+	_, err := time.Parse(time.RFC3339Nano, document["@timestamp"].(string))
+	assert.NoError(t, err)
+}
+
+func TestElasticsearchSink_CustomIndex(t *testing.T) {
+	bulk := &bulkServer{}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "elasticsearch", Address: serveHTTP(t, bulk), Index: "audit"})
+
+	instance.Slog().InfoContext(context.Background(), "custom")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, bulk.indices, 1)
+	assert.True(t, strings.HasPrefix(bulk.indices[0], "audit-"), bulk.indices[0])
+}
+
+func TestElasticsearchSink_PartialFailures(t *testing.T) {
+	bulk := &bulkServer{reject: func(call int, document map[string]any) int {
+		switch {
+		case document["message"] == "invalid":
+			return http.StatusBadRequest
+		case document["message"] == "throttled" && call == 1:
+			return http.StatusTooManyRequests
+		default:
+			return http.StatusCreated
+		}
+	}}
+	fallback := filepath.Join(t.TempDir(), "es-fallback.log")
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "elasticsearch", Address: serveHTTP(t, bulk), FallbackPath: fallback, BatchInterval: time.Hour})
+	ctx := context.Background()
+
+	instance.Slog().InfoContext(ctx, "accepted")
+	instance.Slog().InfoContext(ctx, "invalid")
+	instance.Slog().InfoContext(ctx, "throttled")
+	_ = instance.Shutdown(ctx)
+
+	assert.Equal(t, 2, bulk.calls, "only the throttled document is sent again")
+	var messages []string
+	for _, document := range bulk.documents {
+		messages = append(messages, document["message"].(string))
+	}
+	assert.Equal(t, []string{"accepted", "throttled"}, messages)
+
+	content, err := os.ReadFile(fallback)
+	require.NoError(t, err)
+	entries := decodeLogLines(t, string(content))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "invalid", entries[0]["msg"])
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/httpclient"
+	"github.com/pawatthir/blogger/middleware/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBufferedLogger(t *testing.T, opts ...logger.Option) (*logger.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	opts = append([]logger.Option{
+		logger.WithConfig(logger.Config{Env: "test", Level: "info", UseJSON: true}),
+		logger.WithWriter(&buf),
+	}, opts...)
+	return logger.New(opts...), &buf
+}
+
+func TestNew_InstancesAreIndependent(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "default-service", Level: "info", UseJSON: true})
+	defaultLogger := logger.Default()
+
+	payments, paymentsBuf := newBufferedLogger(t, logger.WithServiceName("payments"), logger.WithVersion("v1.2.3"))
+	orders, ordersBuf := newBufferedLogger(t, logger.WithServiceName("orders"), logger.WithLevel("warn"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			payments.Slog().InfoContext(context.Background(), "payment captured")
+		}()
+		go func() {
+			defer wg.Done()
+			orders.Slog().InfoContext(context.Background(), "order info")
+			orders.Slog().WarnContext(context.Background(), "order warn")
+		}()
+	}
+	wg.Wait()
+
+	paymentEntries := decodeLogLines(t, paymentsBuf.String())
+	require.Len(t, paymentEntries, 20)
+	for _, entry := range paymentEntries {
+		assert.Equal(t, "payment captured", entry["msg"])
+		dd := entry["dd"].(map[string]interface{})
+		assert.Equal(t, "payments", dd["service"])
+		assert.Equal(t, "v1.2.3", dd["version"])
+	}
+
+	orderEntries := decodeLogLines(t, ordersBuf.String())
+	require.Len(t, orderEntries, 20)
+	for _, entry := range orderEntries {
+		assert.Equal(t, "order warn", entry["msg"])
+		assert.Equal(t, "orders", entry["dd"].(map[string]interface{})["service"])
+	}
+
+	assert.Same(t, defaultLogger, logger.Default(), "New must not replace the default logger")
+	assert.Equal(t, "default-service", logger.ServiceName)
+}
+
+func TestNew_LevelsAreScopedToInstance(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "default-service", Level: "info", UseJSON: true})
+	instance, buf := newBufferedLogger(t)
+
+	require.NoError(t, instance.SetLevel("debug"))
+	require.NoError(t, instance.SetLoggerLevel("noisy", "error"))
+
+	assert.Equal(t, "debug", instance.GetLevel())
+	assert.Equal(t, "info", logger.GetLevel())
+	assert.Equal(t, "info", logger.GetLoggerLevel("noisy"))
+
+	instance.Slog().DebugContext(context.Background(), "debug entry")
+	instance.Slog().WarnContext(context.Background(), "noisy warn", "logger_name", "noisy")
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "debug entry", entries[0]["msg"])
+}
+
+func TestNew_CanonicalLoggerUsesInstanceTemplateAndRedactor(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "default-service", Level: "info", UseJSON: true})
+
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		FieldRules: []logger.FieldRule{{Path: "card", Strategy: logger.RedactMask}},
+	})
+	require.NoError(t, err)
+	instance, buf := newBufferedLogger(t,
+		logger.WithRedactor(redactor),
+		logger.WithCanonicalTemplate("{{.Method}} {{.Path}} -> {{.Status}}"),
+	)
+
+	handler := nethttp.NewLoggingMiddlewareWithLogger(instance).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"card":"4111111111111111","amount":10}`))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/charges", strings.NewReader(`{}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "POST /charges -> 200", entries[0]["msg"])
+	body := entries[0]["response"].(map[string]interface{})
+	assert.NotEqual(t, "4111111111111111", body["card"])
+	assert.Equal(t, float64(10), body["amount"])
+}
+
+func TestHTTPClientRoundTripper_WithLogger(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "default-service", Level: "info", UseJSON: true})
+	defaultBuf := captureDefaultSlog(t)
+	instance, buf := newBufferedLogger(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := httpclient.DefaultConfig()
+	config.Logger = instance
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, config)}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Len(t, decodeLogLines(t, buf.String()), 2)
+	assert.Empty(t, defaultBuf.String())
+}
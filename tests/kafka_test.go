@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/logger/kafkatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var kafkaSinkTypes atomic.Int64
+
+// kafkaSinkType registers a sink type of its own for producer, as the registry
+// rejects duplicates, and returns it.
+func kafkaSinkType(t *testing.T, producer logger.KafkaProducer, onDeliveryError logger.KafkaDeliveryErrorFunc) string {
+	sinkType := fmt.Sprintf("kafka-test-%d", kafkaSinkTypes.Add(1))
+	require.NoError(t, logger.RegisterSink(sinkType, func(output logger.OutputConfig) (logger.Sink, error) {
+		return logger.NewKafkaSink(producer, output, onDeliveryError)
+	}))
+	return sinkType
+}
+
+func traceContext(t *testing.T, traceHex string) context.Context {
+	traceID, err := trace.TraceIDFromHex(traceHex)
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+}
+
+func TestKafkaSink_KeysByTraceID(t *testing.T) {
+	producer := &kafkatest.Producer{}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: kafkaSinkType(t, producer, nil), Topic: "audit-logs"}, logger.WithServiceName("audit"))
+	first := traceContext(t, "4bf92f3577b34da6a3ce929d0e0e4736")
+	second := traceContext(t, "0af7651916cd43dd8448eb211c80319c")
+
+	instance.Slog().InfoContext(first, "request started")
+	instance.Slog().InfoContext(second, "other request")
+	instance.Slog().WarnContext(first, "request slow")
+	instance.Slog().InfoContext(context.Background(), "no trace")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, producer.Delivered(), 4)
+	keys := make([]string, len(producer.Delivered()))
+	for i, message := range producer.Delivered() {
+		keys[i] = string(message.Key)
+		assert.Equal(t, "audit-logs", message.Topic)
+		assert.Equal(t, "audit", message.Headers["service"])
+		assert.False(t, message.Time.IsZero())
+	}
+	assert.Equal(t, []string{
+		"4bf92f3577b34da6a3ce929d0e0e4736",
+		"0af7651916cd43dd8448eb211c80319c",
+		"4bf92f3577b34da6a3ce929d0e0e4736",
+		"",
+	}, keys)
+	assert.Nil(t, producer.Delivered()[3].Key)
+	assert.Equal(t, "warn", producer.Delivered()[2].Headers["level"])
+
+	var value map[string]any
+	require.NoError(t, json.Unmarshal(producer.Delivered()[0].Value, &value))
+	assert.Equal(t, "request started", value["msg"])
+	assert.True(t, producer.Closed())
+}
+
+func TestKafkaSink_RetriesOnlyFailedMessages(t *testing.T) {
+	producer := &kafkatest.Producer{Fail: func(call int, message logger.KafkaMessage) error {
+		var value map[string]any
+		_ = json.Unmarshal(message.Value, &value)
+		if call == 1 && value["msg"] == "second" {
+			return errors.New("leader not available")
+		}
+		return nil
+	}}
+	var reported atomic.Int64
+	sinkType := kafkaSinkType(t, producer, func(logger.KafkaMessage, error) { reported.Add(1) })
+	instance := newSinkLogger(t, logger.OutputConfig{Type: sinkType, Topic: "audit-logs", BatchInterval: time.Hour})
+
+	instance.Slog().InfoContext(context.Background(), "first")
+	instance.Slog().InfoContext(context.Background(), "second")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, producer.Calls(), 2)
+	assert.Len(t, producer.Calls()[1], 1)
+	assert.Len(t, producer.Delivered(), 2)
+	assert.Zero(t, reported.Load())
+}
+
+func TestKafkaSink_ReportsDeliveryErrors(t *testing.T) {
+	deliveryErr := errors.New("message too large")
+	producer := &kafkatest.Producer{Fail: func(int, logger.KafkaMessage) error { return deliveryErr }}
+
+	var mu sync.Mutex
+	var failed []logger.KafkaMessage
+	var lastErr error
+	sinkType := kafkaSinkType(t, producer, func(message logger.KafkaMessage, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, message)
+		lastErr = err
+	})
+	instance := newSinkLogger(t, logger.OutputConfig{Type: sinkType, Topic: "audit-logs", MaxRetries: 1})
+
+	instance.Slog().ErrorContext(context.Background(), "undeliverable")
+	assert.Error(t, instance.Shutdown(context.Background()))
+
+	assert.Len(t, producer.Calls(), 2)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "audit-logs", failed[0].Topic)
+	assert.Contains(t, string(failed[0].Value), "undeliverable")
+	assert.ErrorIs(t, lastErr, deliveryErr)
+}
+
+func TestNewKafkaSink_RequiresProducerAndTopic(t *testing.T) {
+	_, err := logger.NewKafkaSink(nil, logger.OutputConfig{Topic: "logs"}, nil)
+	assert.Error(t, err)
+	_, err = logger.NewKafkaSink(&kafkatest.Producer{}, logger.OutputConfig{}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package tests
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchLevelSignals(t *testing.T) {
+	initLevelTest(t, "info")
+	stop := logger.WatchLevelSignals(0)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return logger.GetLevel() == "debug" }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool { return logger.GetLevel() == "info" }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool { return logger.GetLevel() == "warn" }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initLevelTest(t *testing.T, level string) *slog.Logger {
+	slogger := logger.Init(logger.Config{Env: "test", ServiceName: "level-test", Level: level, UseJSON: true})
+	t.Cleanup(func() { logger.Init(logger.Config{Env: "test", ServiceName: "level-test", Level: "info", UseJSON: true}) })
+	return slogger
+}
+
+func TestSetLevel_AppliesToExistingLoggers(t *testing.T) {
+	slogger := initLevelTest(t, "info")
+	ctx := context.Background()
+
+	assert.False(t, slogger.Enabled(ctx, slog.LevelDebug))
+
+	require.NoError(t, logger.SetLevel("debug"))
+	assert.True(t, slogger.Enabled(ctx, slog.LevelDebug))
+	assert.Equal(t, "debug", logger.GetLevel())
+
+	require.NoError(t, logger.SetLevel("error"))
+	assert.False(t, slogger.Enabled(ctx, slog.LevelWarn))
+
+	assert.Error(t, logger.SetLevel("verbose"))
+	assert.Equal(t, "error", logger.GetLevel())
+}
+
+func TestSetLevelWithTTL_Reverts(t *testing.T) {
+	initLevelTest(t, "info")
+
+	require.NoError(t, logger.SetLevelWithTTL("debug", 30*time.Millisecond))
+	assert.Equal(t, "debug", logger.GetLevel())
+
+	// A second bump keeps the original level as the revert target.
+	require.NoError(t, logger.SetLevelWithTTL("warn", 30*time.Millisecond))
+	assert.Equal(t, "warn", logger.GetLevel())
+
+	assert.Eventually(t, func() bool { return logger.GetLevel() == "info" }, time.Second, 5*time.Millisecond)
+}
+
+func TestSetLevel_CancelsPendingRevert(t *testing.T) {
+	initLevelTest(t, "info")
+
+	require.NoError(t, logger.SetLevelWithTTL("debug", 20*time.Millisecond))
+	require.NoError(t, logger.SetLevel("warn"))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "warn", logger.GetLevel())
+}
+
+func TestSetLoggerLevel_PerLoggerName(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{Env: "test", ServiceName: "level-test", Level: "info", UseJSON: true})
+
+	require.NoError(t, logger.SetLoggerLevel("payments", "debug"))
+	require.NoError(t, logger.SetLoggerLevel("canonical", "error"))
+
+	ctx := context.Background()
+	payments := logger.Slog.With(slog.String("logger_name", "payments"))
+	payments.DebugContext(ctx, "payments debug")
+	logger.Slog.DebugContext(ctx, "root debug")
+	logger.Slog.InfoContext(ctx, "canonical info", slog.String("logger_name", "canonical"))
+	logger.Slog.ErrorContext(ctx, "canonical error", slog.String("logger_name", "canonical"))
+	_ = logger.Log.Sync()
+
+	assert.Equal(t, 1, countMessages(t, file, "payments debug"))
+	assert.Equal(t, 0, countMessages(t, file, "root debug"))
+	assert.Equal(t, 0, countMessages(t, file, "canonical info"))
+	assert.Equal(t, 1, countMessages(t, file, "canonical error"))
+
+	assert.Equal(t, "debug", logger.GetLoggerLevel("payments"))
+	logger.ResetLoggerLevel("payments")
+	assert.Equal(t, "info", logger.GetLoggerLevel("payments"))
+}
+
+func TestLevelHandler(t *testing.T) {
+	initLevelTest(t, "info")
+	handler := logger.LevelHandler()
+
+	serve := func(method, target, body string) (int, map[string]string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	status, resp := serve(http.MethodGet, "/log/level", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "info", resp["level"])
+
+	status, resp = serve(http.MethodPut, "/log/level", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "debug", resp["level"])
+	assert.Equal(t, "debug", logger.GetLevel())
+
+	status, resp = serve(http.MethodPut, "/log/level", `{"level":"error","logger_name":"canonical","ttl":"1m"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "canonical", resp["logger_name"])
+
+	status, resp = serve(http.MethodGet, "/log/level?logger_name=canonical", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "error", resp["level"])
+
+	status, resp = serve(http.MethodPut, "/log/level", `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.NotEmpty(t, resp["error"])
+
+	status, _ = serve(http.MethodPut, "/log/level", `{"level":"debug","ttl":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = serve(http.MethodPost, "/log/level", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func TestLevelNames_ParsedTheSameEverywhere(t *testing.T) {
+	for _, name := range []string{"WARN", "Warn"} {
+		config := logger.Config{Env: "test", ServiceName: "level-test", Level: name, UseJSON: true,
+			ComponentLevels: map[string]string{"svc": name}}
+		assert.NoError(t, config.Validate(), name)
+
+		initLevelTest(t, name)
+		assert.Equal(t, "warn", logger.GetLevel(), name)
+		assert.Equal(t, "warn", logger.GetLoggerLevel("svc"), name)
+		require.NoError(t, logger.SetLevel(strings.ToUpper(name)))
+		require.NoError(t, logger.SetLoggerLevel("svc", name))
+	}
+
+	config := logger.Config{Env: "test", ServiceName: "level-test", Level: "info", UseJSON: true,
+		ComponentLevels: map[string]string{"svc": "dpanic"}}
+	assert.Error(t, config.Validate())
+	assert.Error(t, logger.SetLevel("dpanic"))
+	assert.Error(t, logger.SetLoggerLevel("svc", "dpanic"))
+}
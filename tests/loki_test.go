@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiServer records push requests, answering status when it is set.
+type lokiServer struct {
+	mu      sync.Mutex
+	status  int
+	calls   int
+	tenant  string
+	streams []lokiStream
+}
+
+func (l *lokiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls++
+	if r.URL.Path != "/loki/api/v1/push" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if l.status != 0 {
+		w.WriteHeader(l.status)
+		return
+	}
+	l.tenant = r.Header.Get("X-Scope-OrgID")
+	var push struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	l.streams = append(l.streams, push.Streams...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestLokiSink_StreamsByLabels(t *testing.T) {
+	loki := &lokiServer{}
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Type:    "loki",
+		Address: serveHTTP(t, loki),
+		Headers: map[string]string{"X-Scope-OrgID": "team-a"},
+		Labels:  map[string]string{"cluster": "eu-1"},
+	}, logger.WithEnv("prod"), logger.WithServiceName("orders"))
+	ctx := context.Background()
+
+	instance.Slog().InfoContext(ctx, "first")
+	instance.Slog().With("logger_name", "db").InfoContext(ctx, "query")
+	instance.Slog().ErrorContext(ctx, "failed")
+	instance.Slog().InfoContext(ctx, "second")
+	require.NoError(t, instance.Shutdown(ctx))
+
+	assert.Equal(t, "team-a", loki.tenant)
+	require.Len(t, loki.streams, 3)
+
+	info := loki.streams[0]
+	assert.Equal(t, map[string]string{"service": "orders", "env": "prod", "level": "info", "cluster": "eu-1"}, info.Stream)
+	require.Len(t, info.Values, 2)
+	assert.Contains(t, info.Values[0][1], `"msg":"first"`)
+	assert.Contains(t, info.Values[1][1], `"msg":"second"`)
+	assert.LessOrEqual(t, info.Values[0][0], info.Values[1][0])
+
+	assert.Equal(t, "db", loki.streams[1].Stream["logger_name"])
+	assert.Equal(t, "error", loki.streams[2].Stream["level"])
+}
+
+func TestLokiSink_FallsBackToFile(t *testing.T) {
+	loki := &lokiServer{status: http.StatusBadRequest}
+	fallback := filepath.Join(t.TempDir(), "loki-fallback.log")
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "loki", Address: serveHTTP(t, loki), FallbackPath: fallback})
+
+	instance.Slog().WarnContext(context.Background(), "kept locally")
+	assert.Error(t, instance.Shutdown(context.Background()))
+
+	content, err := os.ReadFile(fallback)
+	require.NoError(t, err)
+	entries := decodeLogLines(t, string(content))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "kept locally", entries[0]["msg"])
+	assert.Equal(t, 1, loki.calls, "400 is not retried")
+}
+
+func TestLokiSink_FallsBackAfterRetries(t *testing.T) {
+	loki := &lokiServer{status: http.StatusServiceUnavailable}
+	fallback := filepath.Join(t.TempDir(), "loki-fallback.log")
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "loki", Address: serveHTTP(t, loki), FallbackPath: fallback, MaxRetries: 2})
+
+	instance.Slog().InfoContext(context.Background(), "unreachable")
+	_ = instance.Shutdown(context.Background())
+
+	assert.Equal(t, 3, loki.calls)
+	content, err := os.ReadFile(fallback)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "unreachable")
+}
+
+func TestLokiSink_BlockOverflowAppliesBackpressure(t *testing.T) {
+	loki := &lokiServer{}
+	gate := make(chan struct{})
+	address := serveHTTP(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-gate
+		loki.ServeHTTP(w, r)
+	}))
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "loki", Address: address, BatchSize: 1, QueueSize: 1, Overflow: logger.OverflowBlock})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 4; i++ {
+			instance.Slog().InfoContext(context.Background(), "pressure")
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("logging did not wait for the stalled push")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(gate)
+	<-done
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	total := 0
+	for _, stream := range loki.streams {
+		total += len(stream.Values)
+	}
+	assert.Equal(t, 4, total)
+}
+
+func TestLokiSink_DropNewestNeverBlocks(t *testing.T) {
+	gate := make(chan struct{})
+	address := serveHTTP(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-gate
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "loki", Address: address, BatchSize: 1, QueueSize: 1})
+	defer close(gate)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			instance.Slog().InfoContext(context.Background(), "dropped")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("drop-newest blocked the caller")
+	}
+}
+
+func TestExporterOutputs_Validate(t *testing.T) {
+	err := logger.Config{Outputs: []logger.OutputConfig{
+		{Type: "loki", Overflow: "spill", DropLevel: "loud"},
+		{Type: "elasticsearch"},
+	}}.Validate()
+	assert.Equal(t, []string{
+		"outputs[0].address",
+		"outputs[0].dropLevel",
+		"outputs[0].overflow",
+		"outputs[1].address",
+	}, validationFields(t, err))
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maskingCard struct {
+	Number string `json:"number" sensitive:"true"`
+	Brand  string `json:"brand"`
+}
+
+type maskingOneofWrapper struct {
+	Pin string `protobuf:"bytes,5,opt,name=pin,proto3,oneof" sensitive:"true"`
+}
+
+// Mirrors the shape of protoc-gen-go output, where protojson uses the json= name
+type maskingAccount struct {
+	state     int
+	OwnerName string                  `protobuf:"bytes,1,opt,name=owner_name,json=ownerName,proto3" json:"owner_name,omitempty"`
+	Password  string                  `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty" sensitive:"true"`
+	Cards     []*maskingCard          `protobuf:"bytes,3,rep,name=cards,proto3" json:"cards,omitempty"`
+	Vault     map[string]*maskingCard `protobuf:"bytes,4,rep,name=vault,proto3" json:"vault,omitempty"`
+	Primary   *maskingCard            `protobuf:"bytes,6,opt,name=primary,proto3" json:"primary,omitempty"`
+	Secret    isMaskingSecret         `protobuf_oneof:"secret"`
+}
+
+type isMaskingSecret interface{}
+
+func TestMaskSensitiveFields_Recursive(t *testing.T) {
+	account := &maskingAccount{
+		OwnerName: "John",
+		Password:  "secret123",
+		Cards:     []*maskingCard{{Number: "4111111111111111", Brand: "visa"}, nil},
+		Vault:     map[string]*maskingCard{"backup": {Number: "5500000000000004", Brand: "mc"}},
+		Primary:   &maskingCard{Number: "340000000000009", Brand: "amex"},
+		Secret:    &maskingOneofWrapper{Pin: "1234"},
+	}
+
+	data := map[string]interface{}{
+		"ownerName": "John",
+		"password":  "secret123",
+		"cards": []interface{}{
+			map[string]interface{}{"number": "4111111111111111", "brand": "visa"},
+			nil,
+		},
+		"vault": map[string]interface{}{
+			"backup": map[string]interface{}{"number": "5500000000000004", "brand": "mc"},
+		},
+		"primary": map[string]interface{}{"number": "340000000000009", "brand": "amex"},
+		"pin":     "1234",
+	}
+
+	logger.MaskSensitiveFields(account, data)
+
+	assert.Equal(t, "John", data["ownerName"])
+	assert.Equal(t, "s*****3", data["password"])
+	assert.Equal(t, "4*****1", data["cards"].([]interface{})[0].(map[string]interface{})["number"])
+	assert.Equal(t, "visa", data["cards"].([]interface{})[0].(map[string]interface{})["brand"])
+	assert.Equal(t, "5*****4", data["vault"].(map[string]interface{})["backup"].(map[string]interface{})["number"])
+	assert.Equal(t, "3*****9", data["primary"].(map[string]interface{})["number"])
+	assert.Equal(t, "1*****4", data["pin"])
+}
+
+func TestMaskSensitiveFields_NilInputs(t *testing.T) {
+	assert.NotPanics(t, func() {
+		logger.MaskSensitiveFields(nil, map[string]interface{}{"password": "x"})
+		logger.MaskSensitiveFields((*maskingAccount)(nil), map[string]interface{}{"password": "x"})
+		logger.MaskSensitiveFields(&maskingAccount{}, nil)
+	})
+}
+
+func TestMaskSensitiveJSON(t *testing.T) {
+	masked := logger.MaskSensitiveJSON(&maskingCard{}, []byte(`{"number":"4111111111111111","brand":"visa"}`))
+	assert.JSONEq(t, `{"number":"4*****1","brand":"visa"}`, string(masked))
+
+	notJSON := []byte("plain text")
+	assert.Equal(t, notJSON, logger.MaskSensitiveJSON(&maskingCard{}, notJSON))
+}
+
+func TestLoggingMiddleware_MasksRegisteredModels(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "masking-test", Level: "debug", UseJSON: true})
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddleware(*slogger).Logging())
+	app.Post("/cards", func(c *fiber.Ctx) error {
+		var req maskingCard
+		if err := c.BodyParser(&req); err != nil {
+			return err
+		}
+		httpserver.SetRequestModel(c, &req)
+
+		resp := &maskingCard{Number: req.Number, Brand: strings.ToUpper(req.Brand)}
+		httpserver.SetResponseModel(c, resp)
+		return c.JSON(resp)
+	})
+
+	req := httptest.NewRequest("POST", "/cards", strings.NewReader(`{"number":"card-token-abc","brand":"visa"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{"number": "c*****c", "brand": "visa"}, entry["request"])
+	assert.Equal(t, map[string]interface{}{"number": "c*****c", "brand": "VISA"}, entry["response"])
+}
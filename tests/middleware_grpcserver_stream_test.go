@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/grpcserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Mock server stream that replays a fixed set of inbound messages
+type mockServerStream struct {
+	ctx      context.Context
+	inbound  []string
+	received int
+	sent     []string
+}
+
+func (s *mockServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *mockServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *mockServerStream) SetTrailer(metadata.MD)       {}
+func (s *mockServerStream) Context() context.Context     { return s.ctx }
+
+func (s *mockServerStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m.(*wrapperspb.StringValue).Value)
+	return nil
+}
+
+func (s *mockServerStream) RecvMsg(m interface{}) error {
+	if s.received >= len(s.inbound) {
+		return io.EOF
+	}
+	m.(*wrapperspb.StringValue).Value = s.inbound[s.received]
+	s.received++
+	return nil
+}
+
+func initStreamTestLogger() {
+	logger.Init(logger.Config{
+		Env:         "test",
+		ServiceName: "grpc-stream-test",
+		Level:       "debug",
+		UseJSON:     true,
+	})
+}
+
+func TestNewStreamLoggerInterceptor(t *testing.T) {
+	initStreamTestLogger()
+
+	interceptor := grpcserver.NewStreamLoggerInterceptor(*logger.Slog, true)
+	assert.NotNil(t, interceptor)
+	assert.NotNil(t, interceptor.InterceptStream())
+}
+
+func TestStreamLoggerInterceptor_BidiStream(t *testing.T) {
+	initStreamTestLogger()
+
+	streamInterceptor := grpcserver.NewStreamLoggerInterceptor(*logger.Slog, true).InterceptStream()
+	ss := &mockServerStream{ctx: context.Background(), inbound: []string{"one", "two", "three"}}
+	info := &grpc.StreamServerInfo{
+		FullMethod:     "/test.service/Chat",
+		IsClientStream: true,
+		IsServerStream: true,
+	}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		for {
+			msg := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(msg); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := stream.SendMsg(&wrapperspb.StringValue{Value: "echo " + msg.Value}); err != nil {
+				return err
+			}
+		}
+	}
+
+	err := streamInterceptor(nil, ss, info, handler)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ss.received)
+	assert.Equal(t, []string{"echo one", "echo two", "echo three"}, ss.sent)
+}
+
+func TestStreamLoggerInterceptor_ErrorPropagated(t *testing.T) {
+	initStreamTestLogger()
+
+	streamInterceptor := grpcserver.NewStreamLoggerInterceptor(*logger.Slog, false).InterceptStream()
+	ss := &mockServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.service/ListItems", IsServerStream: true}
+
+	expectedErr := status.Error(codes.Unavailable, "backend unavailable")
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		_ = stream.SendMsg(&wrapperspb.StringValue{Value: "partial"})
+		return expectedErr
+	}
+
+	err := streamInterceptor(nil, ss, info, handler)
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, []string{"partial"}, ss.sent)
+}
+
+func TestStreamLoggerInterceptor_HealthWatchSkipped(t *testing.T) {
+	initStreamTestLogger()
+
+	streamInterceptor := grpcserver.NewStreamLoggerInterceptor(*logger.Slog, false).InterceptStream()
+	ss := &mockServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch", IsServerStream: true}
+
+	var receivedStream grpc.ServerStream
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		receivedStream = stream
+		return nil
+	}
+
+	err := streamInterceptor(nil, ss, info, handler)
+
+	assert.NoError(t, err)
+	assert.Same(t, ss, receivedStream, "health watch should receive the original stream")
+}
+
+func TestGRPCServerStreamInterceptor_PanicWhenLoggerNotInitialized(t *testing.T) {
+	originalSlog := logger.Slog
+	defer func() {
+		logger.Slog = originalSlog
+	}()
+
+	logger.Slog = nil
+
+	assert.Panics(t, func() {
+		grpcserver.GRPCServerStreamInterceptor()
+	})
+}
+
+func TestStreamLoggerInterceptor_RedactsLoggedMessages(t *testing.T) {
+	instance, buf := newBufferedLogger(t, logger.WithLevel("debug"))
+	streamInterceptor := grpcserver.NewStreamLoggerInterceptorWithLogger(instance, true).InterceptStream()
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		msg := &wrapperspb.StringValue{}
+		if err := stream.RecvMsg(msg); err != nil {
+			return err
+		}
+		return stream.SendMsg(&wrapperspb.StringValue{Value: "card 4111 1111 1111 1111"})
+	}
+
+	ss := &mockServerStream{ctx: context.Background(), inbound: []string{"hunter2"}}
+	assert.NoError(t, streamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/auth.v1.Auth/Login"}, handler))
+	assert.Equal(t, "REDACTED", findMessage(t, buf.String(), "Received gRPC stream message on /auth.v1.Auth/Login")["body"])
+
+	buf.Reset()
+	ss = &mockServerStream{ctx: context.Background(), inbound: []string{"hello"}}
+	assert.NoError(t, streamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/orders.v1.Orders/Chat"}, handler))
+	body := findMessage(t, buf.String(), "Sent gRPC stream message on /orders.v1.Orders/Chat")["body"]
+	assert.NotContains(t, body, "4111 1111 1111 1111")
+}
+
+func TestStreamLoggerInterceptor_CanonicalKeepsFirstAndLastMessage(t *testing.T) {
+	instance, buf := newBufferedLogger(t, logger.WithConfig(logger.Config{Env: "test", Level: "debug", UseJSON: true}))
+	streamInterceptor := grpcserver.NewStreamLoggerInterceptorWithLogger(instance, false).InterceptStream()
+	ss := &mockServerStream{ctx: context.Background(), inbound: []string{"one", "two", "three"}}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.service/Chat", IsClientStream: true, IsServerStream: true}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		// The reply is reused, so the interceptor must keep a copy of the last one.
+		reply := &wrapperspb.StringValue{}
+		for {
+			msg := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(msg); err == io.EOF {
+				reply.Value = "after the last send"
+				return nil
+			} else if err != nil {
+				return err
+			}
+			reply.Value = "echo " + msg.Value
+			if err := stream.SendMsg(reply); err != nil {
+				return err
+			}
+		}
+	}
+	require.NoError(t, streamInterceptor(nil, ss, info, handler))
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 1, "messages are not logged one by one when logMessages is false")
+	assert.Equal(t, `"one"`, entries[0]["request"])
+	assert.Equal(t, `"echo three"`, entries[0]["response"])
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRoundTripper struct{}
+
+func (failingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func decodeLogLines(t *testing.T, output string) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLoggingRoundTripper_RequestResponsePair(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "httpclient-test", Level: "debug", UseJSON: true})
+	buf := captureDefaultSlog(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"query":"books"}`, string(body), "server should receive the full body")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"count":2}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: httpclient.HTTPClientRoundTripper(nil)}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/search", strings.NewReader(`{"query":"books"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Request-Id", "req-1")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":2}`, string(respBody), "caller should still read the full response")
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "Sent HTTP Request to POST "+server.URL+"/search", entries[0]["msg"])
+	assert.Equal(t, map[string]interface{}{"query": "books"}, entries[0]["body"])
+	assert.Equal(t, "req-1", entries[0]["headers"].(map[string]interface{})["X-Request-Id"])
+	assert.NotContains(t, buf.String(), "Bearer secret")
+
+	assert.Equal(t, "Received HTTP Response from POST "+server.URL+"/search", entries[1]["msg"])
+	assert.Equal(t, "INFO", entries[1]["level"])
+	assert.Equal(t, float64(200), entries[1]["status_code"])
+	assert.Equal(t, map[string]interface{}{"count": float64(2)}, entries[1]["body"])
+	assert.NotContains(t, buf.String(), "session=abc")
+	assert.NotEmpty(t, entries[1]["duration"])
+}
+
+func TestLoggingRoundTripper_BodyTruncation(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "httpclient-test", Level: "debug", UseJSON: true})
+	buf := captureDefaultSlog(t)
+
+	largeBody := strings.Repeat("x", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(largeBody))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, httpclient.Config{MaxBodyBytes: 10})}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, largeBody, string(respBody))
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 2)
+	assert.Equal(t, strings.Repeat("x", 10), entries[1]["body"])
+	assert.Equal(t, true, entries[1]["body_truncated"])
+}
+
+func TestLoggingRoundTripper_StreamsResponseLazily(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"n\":1}\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("{\"n\":2}\n"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	instance, provider, output := newTracingLogger(t, logger.PropagatorTraceContext)
+	config := httpclient.DefaultConfig()
+	config.Logger = instance
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, config)}
+
+	done := make(chan *http.Response)
+	go func() {
+		resp, err := client.Get(server.URL + "/events")
+		assert.NoError(t, err)
+		done <- resp
+	}()
+	var resp *http.Response
+	select {
+	case resp = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("RoundTrip waited for the streamed body")
+	}
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n", line)
+	assert.NotContains(t, output(), "Received HTTP Response", "the response is logged once the body is done")
+	assert.Empty(t, provider.ended(), "the span covers the body transfer")
+
+	require.NoError(t, resp.Body.Close())
+	entry := findMessage(t, output(), "Received HTTP Response from GET "+server.URL+"/events")
+	assert.Equal(t, true, entry["body_truncated"])
+	assert.Len(t, provider.ended(), 1)
+}
+
+func TestLoggingRoundTripper_RedactsBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"eyJhbGciOi","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	instance, buf := newBufferedLogger(t)
+	config := httpclient.DefaultConfig()
+	config.Logger = instance
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, config)}
+
+	resp, err := client.Post(server.URL+"/oauth/token", "application/json",
+		strings.NewReader(`{"username":"alice","password":"hunter2","card":"4111 1111 1111 1111"}`))
+	require.NoError(t, err)
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Contains(t, string(respBody), "eyJhbGciOi", "the caller still gets the real body")
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 2)
+	request := entries[0]["body"].(map[string]interface{})
+	assert.Equal(t, "alice", request["username"])
+	assert.Equal(t, "*****", request["password"])
+	assert.NotEqual(t, "4111 1111 1111 1111", request["card"])
+	response := entries[1]["body"].(map[string]interface{})
+	assert.Equal(t, "*****", response["access_token"])
+	assert.Equal(t, float64(3600), response["expires_in"])
+}
+
+func TestLoggingRoundTripper_StatusBasedLevel(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "httpclient-test", Level: "debug", UseJSON: true})
+
+	tests := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{"success", http.StatusOK, "INFO"},
+		{"client error", http.StatusNotFound, "WARN"},
+		{"server error", http.StatusBadGateway, "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureDefaultSlog(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: httpclient.HTTPClientRoundTripper(nil)}
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			entries := decodeLogLines(t, buf.String())
+			require.Len(t, entries, 2)
+			assert.Equal(t, tt.expected, entries[1]["level"])
+		})
+	}
+}
+
+func TestLoggingRoundTripper_TransportError(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "httpclient-test", Level: "debug", UseJSON: true})
+	buf := captureDefaultSlog(t)
+
+	client := &http.Client{Transport: httpclient.HTTPClientRoundTripper(failingRoundTripper{})}
+	_, err := client.Get("http://example.invalid/resource")
+	assert.Error(t, err)
+
+	entries := decodeLogLines(t, buf.String())
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ERROR", entries[1]["level"])
+	assert.Equal(t, "connection refused", entries[1]["error"])
+}
@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupNetHTTPTest(t *testing.T) (*bytes.Buffer, slog.Logger) {
+	logger.Init(logger.Config{
+		Env:         "test",
+		ServiceName: "nethttp-test",
+		Level:       "debug",
+		UseJSON:     true,
+	})
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return &buf, *slogger
+}
+
+func decodeCanonicalEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.NotEmpty(t, lines)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+	return entry
+}
+
+func TestNetHTTPLoggingMiddleware_Success(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name":"John"}`, string(body), "handler should still see the request body")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"name":"John"}`))
+	req.Header.Set("X-Request-Id", "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "canonical", entry["logger_name"])
+	assert.Equal(t, map[string]interface{}{"name": "John"}, entry["request"])
+	assert.Equal(t, map[string]interface{}{"id": float64(1)}, entry["response"])
+
+	md := entry["md"].(map[string]interface{})["httpserver_md"].(map[string]interface{})
+	assert.Equal(t, "httpserver", md["type"])
+	assert.Equal(t, "POST", md["method"])
+	assert.Equal(t, "/api/users", md["path"])
+	assert.Equal(t, "req-123", md["x-request-id"])
+}
+
+func TestNetHTTPLoggingMiddleware_ErrorStatus(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/missing", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Contains(t, entry["msg"], "GET 404 /api/missing")
+}
+
+func TestNetHTTPLoggingMiddleware_Sanitization(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"token":"jwt-token"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"password":"secret123"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, buf.String(), "secret123")
+	assert.NotContains(t, buf.String(), "jwt-token")
+}
+
+func TestNetHTTPLoggingMiddleware_PanicRecovery(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("test panic")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	recovered := findMessage(t, buf.String(), "recovered from panic")
+	assert.Equal(t, "ERROR", recovered["level"])
+	assert.Equal(t, "test panic", recovered["panic"])
+	assert.Contains(t, recovered["stack"], "TestNetHTTPLoggingMiddleware_PanicRecovery")
+
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Contains(t, entry["msg"], "GET 500 /panic")
+}
+
+func TestNetHTTPLoggingMiddleware_PanicAfterWriteHeaderLogs500(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("late panic")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/late", nil))
+
+	assert.Contains(t, decodeCanonicalEntry(t, buf)["msg"], "GET 500 /late")
+}
+
+func TestNetHTTPLoggingMiddleware_RepanicsErrAbortHandler(t *testing.T) {
+	buf, slogger := setupNetHTTPTest(t)
+
+	handler := nethttp.NewLoggingMiddleware(slogger).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+	assert.NotContains(t, buf.String(), "recovered from panic")
+}
+
+func TestNetHTTPMiddleware_PanicWhenLoggerNotInitialized(t *testing.T) {
+	originalSlog := logger.Slog
+	defer func() {
+		logger.Slog = originalSlog
+	}()
+
+	logger.Slog = nil
+
+	assert.Panics(t, func() {
+		nethttp.HTTPMiddleware()
+	})
+}
+
+func newBodyLimitedMiddleware(t *testing.T, body logger.BodyLogConfig, handler http.HandlerFunc) (http.Handler, *bytes.Buffer) {
+	instance, buf := newBufferedLogger(t, logger.WithConfig(logger.Config{Env: "test", Level: "info", UseJSON: true, Body: body}))
+	return nethttp.NewLoggingMiddlewareWithLogger(instance).Logging()(handler), buf
+}
+
+func TestNetHTTPLoggingMiddleware_CapturesOnlyTheLoggedPrefix(t *testing.T) {
+	upload := strings.Repeat("a", 1<<20)
+	var received int
+	handler, buf := newBodyLimitedMiddleware(t, logger.BodyLogConfig{MaxRequestBytes: 16, MaxResponseBytes: 8},
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = len(body)
+			w.Write([]byte(strings.Repeat("b", 1000)))
+			w.Write([]byte(strings.Repeat("c", 1000)))
+		})
+
+	// Without Content-Length, the size is counted from what the handler reads.
+	req := httptest.NewRequest(http.MethodPost, "/upload", io.MultiReader(strings.NewReader(upload)))
+	req.ContentLength = -1
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, len(upload), received, "the handler reads the whole body")
+	assert.Equal(t, 2000, recorder.Body.Len())
+
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Equal(t, strings.Repeat("a", 16)+"...[truncated]", entry["request"])
+	assert.Equal(t, float64(len(upload)), entry["request_size"])
+	assert.Equal(t, true, entry["request_truncated"])
+	assert.Equal(t, strings.Repeat("b", 8)+"...[truncated]", entry["response"])
+	assert.Equal(t, float64(2000), entry["response_size"])
+}
+
+func TestNetHTTPLoggingMiddleware_SkipsBinaryBodies(t *testing.T) {
+	handler, buf := newBodyLimitedMiddleware(t, logger.BodyLogConfig{},
+		func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(bytes.Repeat([]byte{0x89}, 512))
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/avatar", strings.NewReader("--abc\r\n\r\nfile-bytes\r\n--abc--"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Equal(t, "[skipped multipart/form-data; boundary=abc body]", entry["request"])
+	assert.Equal(t, float64(req.ContentLength), entry["request_size"])
+	assert.Equal(t, "[skipped image/png body]", entry["response"])
+	assert.Equal(t, float64(512), entry["response_size"])
+}
+
+func TestNetHTTPLoggingMiddleware_CutJSONIsNotLogged(t *testing.T) {
+	handler, buf := newBodyLimitedMiddleware(t, logger.BodyLogConfig{MaxRequestBytes: 20},
+		func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+		})
+
+	body := `{"password":"super-secret-value","padding":"` + strings.Repeat("x", 100) + `"}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(body)))
+
+	entry := decodeCanonicalEntry(t, buf)
+	assert.Equal(t, "[truncated json body]", entry["request"])
+	assert.Equal(t, float64(len(body)), entry["request_size"])
+	assert.NotContains(t, buf.String(), "super-secret")
+}
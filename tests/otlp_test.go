@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpCollector is an in-process OTLP logs collector. The first failures
+// exports are rejected with failWith.
+type otlpCollector struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	mu       sync.Mutex
+	requests []*collogspb.ExportLogsServiceRequest
+	headers  []metadata.MD
+	calls    int
+	failures int
+	failWith codes.Code
+}
+
+func (c *otlpCollector) Export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, status.Error(c.failWith, "collector unavailable")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.headers = append(c.headers, md)
+	c.requests = append(c.requests, request)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func (c *otlpCollector) records() []*logspb.LogRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var records []*logspb.LogRecord
+	for _, request := range c.requests {
+		for _, resourceLogs := range request.ResourceLogs {
+			for _, scopeLogs := range resourceLogs.ScopeLogs {
+				records = append(records, scopeLogs.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func startOTLPCollector(t *testing.T, collector *otlpCollector) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(server, collector)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func attributeMap(attributes []*commonpb.KeyValue) map[string]*commonpb.AnyValue {
+	values := map[string]*commonpb.AnyValue{}
+	for _, attribute := range attributes {
+		values[attribute.Key] = attribute.Value
+	}
+	return values
+}
+
+func spanContext(t *testing.T) (context.Context, trace.TraceID, trace.SpanID) {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	span := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), span), traceID, spanID
+}
+
+func TestOTLPSink_GRPC(t *testing.T) {
+	collector := &otlpCollector{}
+	address := startOTLPCollector(t, collector)
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Type:               "otlp",
+		Address:            address,
+		Insecure:           true,
+		Headers:            map[string]string{"x-tenant": "acme"},
+		ResourceAttributes: map[string]string{"team": "payments"},
+	}, logger.WithEnv("staging"), logger.WithServiceName("orders"), logger.WithVersion("1.4.2"))
+
+	ctx, traceID, spanID := spanContext(t)
+	instance.Slog().WarnContext(ctx, "payment retried", "order_id", "ord_1", "attempt", 2)
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	require.Len(t, collector.requests, 1)
+	resource := attributeMap(collector.requests[0].ResourceLogs[0].Resource.Attributes)
+	assert.Equal(t, "orders", resource["service.name"].GetStringValue())
+	assert.Equal(t, "staging", resource["deployment.environment"].GetStringValue())
+	assert.Equal(t, "1.4.2", resource["service.version"].GetStringValue())
+	assert.Equal(t, "payments", resource["team"].GetStringValue())
+	assert.Equal(t, []string{"acme"}, collector.headers[0].Get("x-tenant"))
+
+	records := collector.records()
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "payment retried", record.Body.GetStringValue())
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_WARN, record.SeverityNumber)
+	assert.Equal(t, "WARN", record.SeverityText)
+	assert.Equal(t, traceID[:], record.TraceId)
+	assert.Equal(t, spanID[:], record.SpanId)
+	assert.NotZero(t, record.TimeUnixNano)
+
+	attributes := attributeMap(record.Attributes)
+	assert.Equal(t, "ord_1", attributes["order_id"].GetStringValue())
+	assert.Equal(t, int64(2), attributes["attempt"].GetIntValue())
+	assert.NotContains(t, attributes, "msg")
+	assert.NotContains(t, attributes, "dd")
+}
+
+func TestOTLPSink_GRPCRetriesUnavailable(t *testing.T) {
+	collector := &otlpCollector{failures: 2, failWith: codes.Unavailable}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "otlp", Address: startOTLPCollector(t, collector), Insecure: true})
+
+	instance.Slog().InfoContext(context.Background(), "eventually delivered")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Equal(t, 3, collector.calls)
+	assert.Len(t, collector.records(), 1)
+}
+
+func TestOTLPSink_GRPCDoesNotRetryInvalidArgument(t *testing.T) {
+	collector := &otlpCollector{failures: 1, failWith: codes.InvalidArgument}
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "otlp", Address: startOTLPCollector(t, collector), Insecure: true})
+
+	instance.Slog().InfoContext(context.Background(), "rejected")
+	_ = instance.Shutdown(context.Background())
+
+	assert.Equal(t, 1, collector.calls)
+	assert.Empty(t, collector.records())
+}
+
+func TestOTLPSink_Batching(t *testing.T) {
+	collector := &otlpCollector{}
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Type:          "otlp",
+		Address:       startOTLPCollector(t, collector),
+		Insecure:      true,
+		BatchSize:     2,
+		BatchInterval: time.Hour,
+	})
+
+	for i := 0; i < 5; i++ {
+		instance.Slog().InfoContext(context.Background(), "batched", "i", i)
+	}
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Len(t, collector.records(), 5)
+	for _, request := range collector.requests {
+		assert.LessOrEqual(t, len(request.ResourceLogs[0].ScopeLogs[0].LogRecords), 2)
+	}
+}
+
+func TestOTLPSink_HTTP(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	var received []*collogspb.ExportLogsServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		request := &collogspb.ExportLogsServiceRequest{}
+		require.NoError(t, proto.Unmarshal(body, request))
+		received = append(received, request)
+	}))
+	defer server.Close()
+
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Type:     "otlp",
+		Protocol: "http",
+		Address:  server.URL,
+		Insecure: true,
+		Headers:  map[string]string{"Authorization": "secret"},
+	})
+	ctx, traceID, _ := spanContext(t)
+	instance.Slog().ErrorContext(ctx, "over http")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, calls)
+	require.Len(t, received, 1)
+	record := received[0].ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, "over http", record.Body.GetStringValue())
+	assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, record.SeverityNumber)
+	assert.Equal(t, traceID[:], record.TraceId)
+}
+
+func TestOTLPSink_Validate(t *testing.T) {
+	err := logger.Config{Outputs: []logger.OutputConfig{
+		{Type: "otlp"},
+		{Type: "otlp", Address: "localhost:4317", Protocol: "thrift"},
+	}}.Validate()
+	assert.Equal(t, []string{"outputs[0].address", "outputs[1].protocol"}, validationFields(t, err))
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/grpcclient"
+	"github.com/pawatthir/blogger/middleware/grpcserver"
+	"github.com/pawatthir/blogger/tests/testpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func newSensitiveAccount() *testpb.Account {
+	return &testpb.Account{
+		OwnerName:   "John",
+		Password:    "secret123",
+		PrimaryCard: &testpb.Card{Number: "card-primary", Brand: "visa"},
+		Cards:       []*testpb.Card{{Number: "card-list", Brand: "mc"}},
+		Vault:       map[string]*testpb.Card{"backup": {Number: "card-vault", Brand: "amex"}},
+		Secret:      &testpb.Account_Pin{Pin: "9876"},
+	}
+}
+
+func assertAccountMasked(t *testing.T, data map[string]interface{}) {
+	assert.Equal(t, "John", data["ownerName"])
+	assert.Equal(t, "s*****3", data["password"])
+	assert.Equal(t, "9*****6", data["pin"])
+
+	primary := data["primaryCard"].(map[string]interface{})
+	assert.Equal(t, "c*****y", primary["number"])
+	assert.Equal(t, "visa", primary["brand"])
+
+	listed := data["cards"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "c*****t", listed["number"])
+
+	vaulted := data["vault"].(map[string]interface{})["backup"].(map[string]interface{})
+	assert.Equal(t, "c*****t", vaulted["number"])
+	assert.Equal(t, "amex", vaulted["brand"])
+}
+
+func TestMaskSensitiveFields_ProtoFieldOption(t *testing.T) {
+	account := newSensitiveAccount()
+
+	jsonBytes, err := protojson.Marshal(account)
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonBytes, &data))
+
+	logger.MaskSensitiveFields(account, data)
+	assertAccountMasked(t, data)
+}
+
+func TestMaskSensitiveFields_ProtoNamesOutput(t *testing.T) {
+	account := newSensitiveAccount()
+
+	jsonBytes, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(account)
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonBytes, &data))
+
+	logger.MaskSensitiveFields(account, data)
+	assert.Equal(t, "s*****3", data["password"])
+	assert.Equal(t, "c*****y", data["primary_card"].(map[string]interface{})["number"])
+}
+
+func TestGRPCServerInterceptor_MasksProtoSensitiveFields(t *testing.T) {
+	// Disable the default key-based rules so only the proto option is exercised
+	logger.Init(logger.Config{Env: "test", ServiceName: "proto-sensitive-test", Level: "debug", UseJSON: true, Redaction: &logger.RedactionConfig{}})
+	defer logger.SetRedactor(nil)
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	interceptor := grpcserver.NewUnaryLoggerInterceptor(*slogger).Intercept()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return newSensitiveAccount(), nil
+	}
+
+	_, err := interceptor(context.Background(), newSensitiveAccount(), &grpc.UnaryServerInfo{FullMethod: "/test.Accounts/Get"}, handler)
+	require.NoError(t, err)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assertAccountMasked(t, entry["request"].(map[string]interface{}))
+	assertAccountMasked(t, entry["response"].(map[string]interface{}))
+	assert.NotContains(t, buf.String(), "secret123")
+}
+
+func TestGRPCClientInterceptor_MasksProtoSensitiveFields(t *testing.T) {
+	logger.Init(logger.Config{Env: "test", ServiceName: "proto-sensitive-test", Level: "debug", UseJSON: true})
+	buf := captureDefaultSlog(t)
+
+	interceptor := grpcclient.UnaryClientLoggingInterceptor()
+	invoker := grpc.UnaryInvoker(func(ctx context.Context, method string, req, resp interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		resp.(*testpb.Account).Password = "response-secret"
+		return nil
+	})
+
+	err := interceptor(context.Background(), "/test.Accounts/Update", newSensitiveAccount(), &testpb.Account{}, &grpc.ClientConn{}, invoker)
+	require.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "secret123")
+	assert.NotContains(t, buf.String(), "response-secret")
+	assert.NotContains(t, buf.String(), "card-primary")
+}
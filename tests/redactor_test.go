@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedactor_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config logger.RedactionConfig
+	}{
+		{
+			name:   "unknown strategy",
+			config: logger.RedactionConfig{FieldRules: []logger.FieldRule{{Path: "*.password", Strategy: "scramble"}}},
+		},
+		{
+			name:   "invalid regex",
+			config: logger.RedactionConfig{ValueRules: []logger.ValueRule{{Name: "broken", Pattern: "([", Strategy: logger.RedactMask}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logger.NewRedactor(tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRedactor_FieldRules(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		FieldRules: []logger.FieldRule{
+			{Path: "$.user.password", Strategy: logger.RedactDrop},
+			{Path: "*.card_number", Strategy: logger.RedactPartial},
+			{Path: "$.items[*].*.cvv", Strategy: logger.RedactMask},
+			{Path: "ssn", Strategy: logger.RedactHash},
+		},
+	})
+	require.NoError(t, err)
+
+	body := []byte(`{
+		"password": "top-level-is-not-anchored",
+		"user": {"name": "John", "password": "secret123", "ssn": "123-45-6789"},
+		"payment": {"card_number": "4111111111111111"},
+		"items": [{"card": {"cvv": "123", "brand": "visa"}}]
+	}`)
+
+	result, ok := redactor.RedactBody("/api/orders", body).(map[string]interface{})
+	require.True(t, ok)
+
+	assert.Equal(t, "top-level-is-not-anchored", result["password"])
+
+	user := result["user"].(map[string]interface{})
+	assert.NotContains(t, user, "password")
+	assert.Equal(t, "John", user["name"])
+	assert.True(t, strings.HasPrefix(user["ssn"].(string), "sha256:"))
+
+	assert.Equal(t, "4*****1", result["payment"].(map[string]interface{})["card_number"])
+
+	card := result["items"].([]interface{})[0].(map[string]interface{})["card"].(map[string]interface{})
+	assert.Equal(t, "*****", card["cvv"])
+	assert.Equal(t, "visa", card["brand"])
+}
+
+func TestRedactor_HashIsStable(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		FieldRules: []logger.FieldRule{{Path: "*.email", Strategy: logger.RedactHash}},
+	})
+	require.NoError(t, err)
+
+	first := redactor.RedactBody("/a", []byte(`{"email":"john@example.com"}`)).(map[string]interface{})
+	second := redactor.RedactBody("/b", []byte(`{"email":"john@example.com"}`)).(map[string]interface{})
+	assert.Equal(t, first["email"], second["email"])
+	assert.NotEqual(t, "john@example.com", first["email"])
+}
+
+func TestRedactor_ValueRules(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		ValueRules: []logger.ValueRule{
+			{Name: "email", Pattern: "email", Strategy: logger.RedactMask},
+			{Name: "credit_card", Pattern: "credit_card", Strategy: logger.RedactDrop},
+			{Name: "thai_national_id", Pattern: "thai_national_id", Strategy: logger.RedactPartial},
+			{Name: "order_ref", Pattern: `ORD-\d+`, Strategy: logger.RedactMask},
+		},
+	})
+	require.NoError(t, err)
+
+	body := []byte(`{
+		"note": "contact john@example.com about ORD-42",
+		"card": "4111 1111 1111 1111",
+		"not_a_card": "1234567890123",
+		"citizen_id": "1101700230708"
+	}`)
+
+	result := redactor.RedactBody("/api/customers", body).(map[string]interface{})
+	assert.Equal(t, "contact ***** about *****", result["note"])
+	assert.Equal(t, "[REDACTED]", result["card"])
+	assert.Equal(t, "1234567890123", result["not_a_card"], "numbers failing the Luhn check are kept")
+	assert.Equal(t, "1*****8", result["citizen_id"])
+}
+
+func TestRedactor_ValueRulesOnNonJSONBody(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		ValueRules: []logger.ValueRule{{Name: "email", Pattern: "email", Strategy: logger.RedactMask}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user=***** action=login", redactor.RedactBody("/form", []byte("user=jane@example.org action=login")))
+}
+
+func TestRedactor_PathRules(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		PathRules: []logger.PathRule{{Pattern: "verify-otp", Strategy: logger.RedactDrop}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "REDACTED", redactor.RedactBody("/api/auth/VERIFY-OTP", []byte(`{"otp":"123456"}`)))
+	assert.Equal(t, map[string]interface{}{"otp": "123456"}, redactor.RedactBody("/api/profile", []byte(`{"otp":"123456"}`)))
+}
+
+func TestRedactor_TopLevelArray(t *testing.T) {
+	redactor := logger.NewDefaultRedactor()
+
+	result, ok := redactor.RedactBody("/users", []byte(`[{"name":"a","password":"hunter2"},{"name":"b","token":"t-1"}]`)).([]interface{})
+	require.True(t, ok)
+	require.Len(t, result, 2)
+	assert.Equal(t, map[string]interface{}{"name": "a", "password": "*****"}, result[0])
+	assert.Equal(t, map[string]interface{}{"name": "b", "token": "*****"}, result[1])
+
+	payload := redactor.RedactPayload([]byte(`[{"password":"hunter2"}]`))
+	assert.NotContains(t, fmt.Sprint(payload), "hunter2")
+}
+
+func TestRedactor_NestedArraysOfObjects(t *testing.T) {
+	redactor, err := logger.NewRedactor(logger.RedactionConfig{
+		FieldRules: []logger.FieldRule{{Path: "*.secret", Strategy: logger.RedactDrop}},
+	})
+	require.NoError(t, err)
+
+	result := redactor.RedactBody("/batches", []byte(`[{"items":[[{"id":1,"secret":"s-1"}],[{"id":2,"secret":"s-2"}]]}]`))
+	raw, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"items":[[{"id":1}],[{"id":2}]]}]`, string(raw))
+}
+
+func TestDefaultRedactor_MasksOnlySensitiveFields(t *testing.T) {
+	redactor := logger.NewDefaultRedactor()
+
+	result := redactor.RedactBody("/api/users", []byte(`{"username":"user","password":"secret123"}`)).(map[string]interface{})
+	assert.Equal(t, "user", result["username"])
+	assert.Equal(t, "*****", result["password"])
+}
+
+func TestDefaultRedactor_DropsDenyPatternPaths(t *testing.T) {
+	redactor := logger.NewDefaultRedactor()
+	for _, path := range []string{"/api/auth/login", "/verify-otp", "/auth.v1.Auth/RefreshToken", "/refresh-token"} {
+		assert.Equal(t, "REDACTED", redactor.RedactBody(path, []byte(`{"username":"user"}`)), path)
+	}
+
+	original := logger.DenyPatterns
+	defer func() { logger.DenyPatterns = original }()
+	logger.DenyPatterns = append(logger.DenyPatterns, "payment")
+	assert.Equal(t, "REDACTED", logger.NewDefaultRedactor().RedactBody("/v1/payment", []byte(`{"amount":10}`)))
+}
+
+func TestCanonicalLogger_UsesConfiguredRedactor(t *testing.T) {
+	logger.Init(logger.Config{
+		Env:         "test",
+		ServiceName: "redactor-test",
+		Level:       "debug",
+		UseJSON:     true,
+		Redaction: &logger.RedactionConfig{
+			FieldRules: []logger.FieldRule{{Path: "$.user.password", Strategy: logger.RedactMask}},
+		},
+	})
+	defer logger.SetRedactor(nil)
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	request := []byte(`{"user":{"name":"John","password":"secret123"}}`)
+	response := []byte(`{"token":"left-alone-by-custom-rules"}`)
+	canonicalLog := logger.CanonicalLog{Transport: "HTTP", Traffic: "incoming", Method: "POST", Status: 200, Path: "/api/auth/login"}
+
+	logger.CanonicalLogger(context.Background(), *slogger, logger.Info, request, response, nil, canonicalLog, []any{})
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{"name": "John", "password": "*****"}, entry["request"].(map[string]interface{})["user"])
+	assert.Equal(t, "left-alone-by-custom-rules", entry["response"].(map[string]interface{})["token"])
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/grpcclient"
+	"github.com/pawatthir/blogger/middleware/grpcserver"
+	"github.com/pawatthir/blogger/middleware/httpclient"
+	"github.com/pawatthir/blogger/middleware/httpserver"
+	"github.com/pawatthir/blogger/middleware/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNewRequestID_IsUUIDv7(t *testing.T) {
+	id, err := uuid.Parse(logger.NewRequestID())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+	assert.NotEqual(t, logger.NewRequestID(), logger.NewRequestID())
+}
+
+func TestRequestIDOrNew(t *testing.T) {
+	assert.Equal(t, "req-123", logger.RequestIDOrNew("req-123"))
+
+	for _, invalid := range []string{"", "has space", "line\nbreak", strings.Repeat("a", 129)} {
+		id := logger.RequestIDOrNew(invalid)
+		assert.NotEqual(t, invalid, id)
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err, "replacement for %q", invalid)
+	}
+}
+
+func TestFiberMiddleware_GeneratesRequestID(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/", func(c *fiber.Ctx) error {
+		logger.FromContext(c.UserContext()).InfoContext(c.UserContext(), "handling")
+		return c.SendString("ok")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+
+	requestID := resp.Header.Get("X-Request-Id")
+	require.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, findMessage(t, buf.String(), "handling")["request_id"])
+}
+
+func TestFiberMiddleware_EchoesRequestID(t *testing.T) {
+	instance, _ := newBufferedLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(logger.RequestIDFromContext(c.UserContext()))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-Id", "req-fiber")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "req-fiber", resp.Header.Get("X-Request-Id"))
+}
+
+func TestNetHTTPMiddleware_GeneratesRequestID(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	var seen string
+	handler := nethttp.NewLoggingMiddlewareWithLogger(instance).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logger.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/items", nil))
+
+	require.NotEmpty(t, seen)
+	assert.Equal(t, seen, recorder.Header().Get("X-Request-Id"))
+	assert.Contains(t, buf.String(), `"x-request-id":"`+seen+`"`)
+}
+
+func TestGRPCInterceptor_RequestIDFromMetadata(t *testing.T) {
+	instance, _ := newBufferedLogger(t)
+	interceptor := grpcserver.NewUnaryLoggerInterceptorWithLogger(instance).Intercept()
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+	var seen string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = logger.RequestIDFromContext(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-grpc"))
+	_, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "req-grpc", seen)
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	_, err = uuid.Parse(seen)
+	assert.NoError(t, err)
+}
+
+func TestGRPCClientInterceptor_ForwardsRequestID(t *testing.T) {
+	instance, _ := newBufferedLogger(t)
+	interceptor := grpcclient.UnaryClientLoggingInterceptorWithLogger(instance)
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, resp interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	ctx := logger.WithRequestID(context.Background(), "req-out")
+	require.NoError(t, interceptor(ctx, "/orders.v1.Orders/Get", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-out"}, sent.Get("x-request-id"))
+
+	// An ID the caller set explicitly is kept.
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-explicit")
+	require.NoError(t, interceptor(ctx, "/orders.v1.Orders/Get", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-explicit"}, sent.Get("x-request-id"))
+}
+
+func TestHTTPClient_ForwardsRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Request-Id")
+	}))
+	defer server.Close()
+
+	instance, _ := newBufferedLogger(t)
+	config := httpclient.DefaultConfig()
+	config.Logger = instance
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, config)}
+
+	req, err := http.NewRequestWithContext(logger.WithRequestID(context.Background(), "req-http-out"), "GET", server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "req-http-out", received)
+	assert.Empty(t, req.Header.Get("X-Request-Id"), "the caller's request is not modified")
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initWithStdoutFile points os.Stdout at a temp file while the logger is built so
+// the zap cores write somewhere the test can read back.
+func initWithStdoutFile(t *testing.T, cfg logger.Config) *os.File {
+	file, err := os.CreateTemp("", "sampling-*.log")
+	require.NoError(t, err)
+
+	original := os.Stdout
+	os.Stdout = file
+	logger.Init(cfg)
+	os.Stdout = original
+
+	t.Cleanup(func() {
+		logger.Init(logger.Config{Env: "test", ServiceName: "sampling-test", Level: "debug", UseJSON: true})
+		file.Close()
+		os.Remove(file.Name())
+	})
+	return file
+}
+
+func countMessages(t *testing.T, file *os.File, msg string) int {
+	data, err := os.ReadFile(file.Name())
+	require.NoError(t, err)
+
+	count := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry map[string]interface{}
+		if json.Unmarshal(line, &entry) == nil && entry["msg"] == msg {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSampling_FirstNThenEveryM(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "debug", UseJSON: true,
+		Sampling: logger.SamplingConfig{Enabled: true, Initial: 2, Thereafter: 5, Tick: time.Minute},
+	})
+
+	before := logger.SamplingDropped()
+	for i := 0; i < 12; i++ {
+		logger.Log.Info("hot path")
+	}
+	_ = logger.Log.Sync()
+
+	// Entries 1, 2, 7 and 12 are kept.
+	assert.Equal(t, 4, countMessages(t, file, "hot path"))
+	assert.Equal(t, uint64(8), logger.SamplingDropped().Sampler-before.Sampler)
+}
+
+func TestSampling_PerLevelRates(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "debug", UseJSON: true,
+		Sampling: logger.SamplingConfig{Enabled: true, Levels: map[string]float64{"debug": 0, "info": 1}},
+	})
+
+	before := logger.SamplingDropped()
+	for i := 0; i < 5; i++ {
+		logger.Log.Debug("debug entry")
+		logger.Log.Info("info entry")
+	}
+	_ = logger.Log.Sync()
+
+	assert.Equal(t, 0, countMessages(t, file, "debug entry"))
+	assert.Equal(t, 5, countMessages(t, file, "info entry"))
+	assert.Equal(t, uint64(5), logger.SamplingDropped().LevelRate-before.LevelRate)
+}
+
+func TestSampling_CanonicalPathRules(t *testing.T) {
+	initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "debug", UseJSON: true,
+		Sampling: logger.SamplingConfig{
+			Enabled: true,
+			Paths:   []logger.PathSamplingRule{{Pattern: "/health", Rate: 0, StatusCodes: []int{200}}},
+		},
+	})
+
+	var buf bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&buf, nil))
+	log := func(path string, status int, level logger.Level, err error) {
+		logger.CanonicalLogger(context.Background(), *slogger, level, nil, nil, err,
+			logger.CanonicalLog{Transport: "http", Method: "GET", Status: status, Path: path}, []any{})
+	}
+
+	before := logger.SamplingDropped()
+	log("/health", 200, logger.Info, nil)
+	log("/readiness/HEALTH", 200, logger.Info, nil)
+	assert.Empty(t, buf.String())
+	assert.Equal(t, uint64(2), logger.SamplingDropped().PathRule-before.PathRule)
+
+	log("/health", 503, logger.Error, nil)
+	log("/health", 200, logger.Info, errors.New("probe failed"))
+	log("/api/users", 200, logger.Info, nil)
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+}
+
+func TestSampling_ReportsDroppedEntries(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "debug", UseJSON: true,
+		Sampling: logger.SamplingConfig{
+			Enabled:        true,
+			Levels:         map[string]float64{"debug": 0},
+			ReportInterval: 20 * time.Millisecond,
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		logger.Log.Debug("dropped entry")
+	}
+
+	require.Eventually(t, func() bool {
+		return countMessages(t, file, "log entries dropped by sampling") > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLoadFromFile_Sampling(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "config-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(`log:
+  env: production
+  serviceName: edge-service
+  sampling:
+    enabled: true
+    initial: 100
+    thereafter: 50
+    tick: 1s
+    reportInterval: 1m
+    levels:
+      debug: 0.1
+    paths:
+      - pattern: /health
+        rate: 0.01
+        statusCodes: [200]`)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	got, err := config.LoadFromFile(tmpFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, logger.SamplingConfig{
+		Enabled:        true,
+		Initial:        100,
+		Thereafter:     50,
+		Tick:           time.Second,
+		ReportInterval: time.Minute,
+		Levels:         map[string]float64{"debug": 0.1},
+		Paths:          []logger.PathSamplingRule{{Pattern: "/health", Rate: 0.01, StatusCodes: []int{200}}},
+	}, got.Sampling)
+}
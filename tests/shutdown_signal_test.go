@@ -0,0 +1,62 @@
+//go:build !windows
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shutdownSignalHelperEnv = "BLOGGER_SHUTDOWN_SIGNAL_LOG"
+
+// TestShutdownOnSignal_HelperProcess is the child process of
+// TestShutdownOnSignal; it only runs when the parent sets the log path.
+func TestShutdownOnSignal_HelperProcess(t *testing.T) {
+	path := os.Getenv(shutdownSignalHelperEnv)
+	if path == "" {
+		t.Skip("helper process")
+	}
+
+	logger.Init(logger.Config{
+		Env: "test", ServiceName: "shutdown-signal-test", Level: "info",
+		FileEnabled: true, FilePath: path, FileSize: 1, MaxAge: 1, MaxBackups: 1,
+	})
+	logger.ShutdownOnSignal(time.Second)
+
+	logger.Slog.InfoContext(context.Background(), "last line before SIGTERM")
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	// The re-raised signal ends the process; the parent bounds the wait.
+	select {}
+}
+
+func TestShutdownOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestShutdownOnSignal_HelperProcess$")
+	cmd.Env = append(os.Environ(), shutdownSignalHelperEnv+"="+path)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "helper should be killed by the re-raised signal, got %v", err)
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	require.True(t, ok)
+	assert.True(t, status.Signaled())
+	assert.Equal(t, syscall.SIGTERM, status.Signal())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "last line before SIGTERM")
+	assert.Contains(t, string(content), "shutting down logger on signal")
+}
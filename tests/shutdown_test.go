@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFileLogger(t *testing.T) (*logger.Logger, string) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	instance := logger.New(
+		logger.WithConfig(logger.Config{
+			Env: "test", ServiceName: "shutdown-test", Level: "info",
+			FileEnabled: true, FilePath: path, FileSize: 1, MaxAge: 1, MaxBackups: 1,
+		}),
+		logger.WithWriter(&strings.Builder{}),
+	)
+	return instance, path
+}
+
+func TestShutdown_FlushesAndClosesFile(t *testing.T) {
+	instance, path := newFileLogger(t)
+	ctx := context.Background()
+
+	instance.Slog().InfoContext(ctx, "before shutdown")
+	require.NoError(t, instance.Shutdown(ctx))
+	instance.Slog().InfoContext(ctx, "after shutdown")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "before shutdown")
+	assert.NotContains(t, string(content), "after shutdown", "entries after Shutdown must be dropped")
+}
+
+func TestShutdown_IsIdempotent(t *testing.T) {
+	instance, _ := newFileLogger(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, instance.Shutdown(ctx))
+	assert.NoError(t, instance.Shutdown(ctx))
+	assert.NoError(t, instance.Close())
+}
+
+func TestShutdown_DefaultLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger.Init(logger.Config{
+		Env: "test", ServiceName: "shutdown-test", Level: "info",
+		FileEnabled: true, FilePath: path, FileSize: 1, MaxAge: 1, MaxBackups: 1,
+	})
+	t.Cleanup(func() { logger.Init(logger.Config{Env: "test", ServiceName: "shutdown-test", Level: "info", UseJSON: true}) })
+
+	logger.Slog.InfoContext(context.Background(), "last words")
+	require.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "last words")
+}
+
+func TestInit_ShutsDownReplacedLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger.Init(logger.Config{
+		Env: "test", ServiceName: "shutdown-test", Level: "info",
+		FileEnabled: true, FilePath: path, FileSize: 1, MaxAge: 1, MaxBackups: 1,
+		Async: logger.AsyncConfig{Enabled: true, FlushInterval: time.Hour},
+	})
+	t.Cleanup(func() { logger.Init(logger.Config{Env: "test", ServiceName: "shutdown-test", Level: "info", UseJSON: true}) })
+
+	replaced := logger.Default()
+	logger.Slog.InfoContext(context.Background(), "queued before reload")
+	logger.Init(logger.Config{Env: "test", ServiceName: "shutdown-test", Level: "info", UseJSON: true})
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "queued before reload", "Init must drain the replaced Logger's queues")
+	assert.NoError(t, replaced.Close(), "the replaced Logger is already shut down")
+}
+
+func TestInit_LoggerCapturedBeforeReloadStillWrites(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{
+		Env: "test", ServiceName: "shutdown-test", Level: "info", UseJSON: true,
+		Async: logger.AsyncConfig{Enabled: true, FlushInterval: time.Hour},
+	})
+	captured := logger.Slog
+	captured.InfoContext(context.Background(), "before reload")
+
+	logger.Init(logger.Config{Env: "test", ServiceName: "shutdown-test", Level: "info", UseJSON: true})
+	captured.InfoContext(context.Background(), "after reload")
+
+	assert.Equal(t, 1, countMessages(t, file, "before reload"))
+	assert.Equal(t, 1, countMessages(t, file, "after reload"), "a logger taken before Init must keep writing")
+}
@@ -0,0 +1,307 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// newOutputsLogger builds a debug-level Logger writing to outputs and closes it
+// when the test ends. opts change single settings, such as the service name.
+func newOutputsLogger(t *testing.T, outputs []logger.OutputConfig, opts ...logger.Option) *logger.Logger {
+	opts = append([]logger.Option{logger.WithConfig(logger.Config{Env: "test", ServiceName: "sinks-test", Level: "debug", Outputs: outputs})}, opts...)
+	instance := logger.New(opts...)
+	t.Cleanup(func() { _ = instance.Close() })
+	return instance
+}
+
+// newSinkLogger is newOutputsLogger for a single output, which retries after a
+// millisecond unless it sets MinBackoff.
+func newSinkLogger(t *testing.T, output logger.OutputConfig, opts ...logger.Option) *logger.Logger {
+	if output.MinBackoff == 0 {
+		output.MinBackoff = time.Millisecond
+	}
+	return newOutputsLogger(t, []logger.OutputConfig{output}, opts...)
+}
+
+// serveHTTP starts a fake collector for handler and returns its URL.
+func serveHTTP(t *testing.T, handler http.Handler) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestOutputs_EncoderAndLevelPerOutput(t *testing.T) {
+	var stdout bytes.Buffer
+	path := filepath.Join(t.TempDir(), "errors.log")
+	instance := newOutputsLogger(t, []logger.OutputConfig{
+		{Name: "terminal", Type: "stdout", Encoder: "console"},
+		{Name: "errors", Type: "file", Path: path, Level: "error"},
+	}, logger.WithWriter(&stdout))
+
+	instance.Slog().InfoContext(context.Background(), "routine")
+	instance.Slog().ErrorContext(context.Background(), "broken")
+	require.NoError(t, instance.Shutdown(context.Background()))
+
+	assert.Contains(t, stdout.String(), "routine")
+	assert.Contains(t, stdout.String(), "broken")
+	assert.False(t, strings.HasPrefix(stdout.String(), "{"), "stdout output should use the console encoder")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	entries := decodeLogLines(t, string(content))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "broken", entries[0]["msg"])
+}
+
+func TestOutputs_InvalidOutputFallsBackToStdout(t *testing.T) {
+	var stdout bytes.Buffer
+	instance := newOutputsLogger(t, []logger.OutputConfig{{Name: "nowhere", Type: "carrier-pigeon"}}, logger.WithWriter(&stdout))
+
+	instance.Slog().InfoContext(context.Background(), "still logged")
+	assert.Contains(t, stdout.String(), "invalid outputs, skipping them")
+	assert.Contains(t, stdout.String(), "still logged")
+}
+
+// lineServer accepts TCP connections and collects the lines they send.
+type lineServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+	lines    []string
+}
+
+func startLineServer(t *testing.T, address string) *lineServer {
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	server := &lineServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns = append(server.conns, conn)
+			server.mu.Unlock()
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					server.mu.Lock()
+					server.lines = append(server.lines, scanner.Text())
+					server.mu.Unlock()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(server.stop)
+	return server
+}
+
+// stop closes the listener and every accepted connection, like a restarting
+// collector.
+func (s *lineServer) stop() {
+	s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *lineServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lines...)
+}
+
+func TestOutputs_TCPReconnects(t *testing.T) {
+	server := startLineServer(t, "127.0.0.1:0")
+	address := server.listener.Addr().String()
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Name: "collector", Type: "tcp", Address: address,
+		DialTimeout: time.Second, MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	instance.Slog().InfoContext(ctx, "before restart")
+	assert.Eventually(t, func() bool { return len(server.received()) == 1 }, time.Second, 5*time.Millisecond)
+
+	server.stop()
+	for i := 0; i < 3; i++ {
+		instance.Slog().InfoContext(ctx, "while down")
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	restarted := startLineServer(t, address)
+	assert.Eventually(t, func() bool {
+		instance.Slog().InfoContext(ctx, "after restart")
+		return len(restarted.received()) > 0
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Contains(t, restarted.received()[0], "after restart")
+}
+
+func TestOutputs_TCPUnreachableDoesNotBlockWrites(t *testing.T) {
+	// 10.255.255.1 is not routed, so a dial hangs until DialTimeout.
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Name: "blackhole", Type: "tcp", Address: "10.255.255.1:9",
+		DialTimeout: 5 * time.Second, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond,
+	})
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		instance.Slog().InfoContext(context.Background(), "into the void")
+		time.Sleep(time.Millisecond)
+	}
+	assert.Less(t, time.Since(start), time.Second, "writes must not wait for the dial")
+}
+
+func TestOutputs_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "udp", Address: conn.LocalAddr().String()})
+	instance.Slog().WarnContext(context.Background(), "datagram")
+
+	buf := make([]byte, 64*1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	entries := decodeLogLines(t, string(buf[:n]))
+	assert.Equal(t, "datagram", entries[0]["msg"])
+}
+
+func TestOutputs_SyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	instance := newSinkLogger(t, logger.OutputConfig{
+		Type: "syslog", Address: conn.LocalAddr().String(), Facility: "local0",
+	})
+	instance.Slog().ErrorContext(context.Background(), "disk full")
+
+	buf := make([]byte, 64*1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	message := string(buf[:n])
+
+	// local0 (16) * 8 + error (3)
+	assert.True(t, strings.HasPrefix(message, "<131>1 "), message)
+	parts := strings.SplitN(message, " ", 8)
+	require.Len(t, parts, 8)
+	assert.Equal(t, "sinks-test", parts[3])
+	assert.Equal(t, strconv.Itoa(os.Getpid()), parts[4])
+	assert.Equal(t, "-", parts[5])
+	assert.Contains(t, parts[7], `"msg":"disk full"`)
+	assert.False(t, strings.HasSuffix(message, "\n"))
+}
+
+func TestOutputs_SyslogTCPUsesOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(length))
+		message := make([]byte, size)
+		if _, err := reader.Read(message); err == nil {
+			received <- string(message)
+		}
+	}()
+
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "syslog", Network: "tcp", Address: listener.Addr().String()})
+	instance.Slog().InfoContext(context.Background(), "framed")
+
+	select {
+	case message := <-received:
+		assert.True(t, strings.HasPrefix(message, "<14>1 "), message)
+		assert.Contains(t, message, `"msg":"framed"`)
+	case <-time.After(time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
+
+type recordingSink struct {
+	mu      sync.Mutex
+	levels  []zapcore.Level
+	entries []string
+}
+
+func (s *recordingSink) Write(entry zapcore.Entry, encoded []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.levels = append(s.levels, entry.Level)
+	s.entries = append(s.entries, string(encoded))
+	return nil
+}
+
+func (s *recordingSink) Sync() error  { return nil }
+func (s *recordingSink) Close() error { return nil }
+
+func TestRegisterSink(t *testing.T) {
+	sink := &recordingSink{}
+	var options map[string]string
+	require.NoError(t, logger.RegisterSink("recording-test", func(output logger.OutputConfig) (logger.Sink, error) {
+		options = output.Options
+		return sink, nil
+	}))
+	assert.Error(t, logger.RegisterSink("recording-test", nil), "duplicate types must be rejected")
+	assert.Contains(t, logger.SinkTypes(), "recording-test")
+
+	instance := newSinkLogger(t, logger.OutputConfig{Type: "recording-test", Options: map[string]string{"topic": "logs"}})
+	instance.Slog().WarnContext(context.Background(), "custom sink")
+
+	assert.Equal(t, map[string]string{"topic": "logs"}, options)
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, zapcore.WarnLevel, sink.levels[0])
+	assert.Contains(t, sink.entries[0], "custom sink")
+}
+
+func TestOutputs_Validate(t *testing.T) {
+	err := logger.Config{Outputs: []logger.OutputConfig{
+		{Name: "a", Type: "file"},
+		{Name: "a", Type: "tcp", Encoder: "xml", Level: "loud"},
+		{Type: "syslog", Address: "localhost:514", Network: "sctp", Facility: "mars"},
+		{Type: "nowhere"},
+	}}.Validate()
+	assert.Equal(t, []string{
+		"outputs[0].path",
+		"outputs[1].address",
+		"outputs[1].encoder",
+		"outputs[1].level",
+		"outputs[1].name",
+		"outputs[2].facility",
+		"outputs[2].network",
+		"outputs[3].type",
+	}, validationFields(t, err))
+}
@@ -0,0 +1,3 @@
+package testpb
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative tests/testpb/masking.proto
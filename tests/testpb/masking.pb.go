@@ -0,0 +1,322 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: tests/testpb/masking.proto
+
+package testpb
+
+import (
+	_ "github.com/pawatthir/blogger/bloggerpb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Card struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number string `protobuf:"bytes,1,opt,name=number,proto3" json:"number,omitempty"`
+	Brand  string `protobuf:"bytes,2,opt,name=brand,proto3" json:"brand,omitempty"`
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tests_testpb_masking_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_tests_testpb_masking_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_tests_testpb_masking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Card) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *Card) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+type Account struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OwnerName   string           `protobuf:"bytes,1,opt,name=owner_name,json=ownerName,proto3" json:"owner_name,omitempty"`
+	Password    string           `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	PrimaryCard *Card            `protobuf:"bytes,3,opt,name=primary_card,json=primaryCard,proto3" json:"primary_card,omitempty"`
+	Cards       []*Card          `protobuf:"bytes,4,rep,name=cards,proto3" json:"cards,omitempty"`
+	Vault       map[string]*Card `protobuf:"bytes,5,rep,name=vault,proto3" json:"vault,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Types that are assignable to Secret:
+	//	*Account_Pin
+	//	*Account_Hint
+	Secret isAccount_Secret `protobuf_oneof:"secret"`
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_tests_testpb_masking_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_tests_testpb_masking_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_tests_testpb_masking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Account) GetOwnerName() string {
+	if x != nil {
+		return x.OwnerName
+	}
+	return ""
+}
+
+func (x *Account) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Account) GetPrimaryCard() *Card {
+	if x != nil {
+		return x.PrimaryCard
+	}
+	return nil
+}
+
+func (x *Account) GetCards() []*Card {
+	if x != nil {
+		return x.Cards
+	}
+	return nil
+}
+
+func (x *Account) GetVault() map[string]*Card {
+	if x != nil {
+		return x.Vault
+	}
+	return nil
+}
+
+func (m *Account) GetSecret() isAccount_Secret {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+func (x *Account) GetPin() string {
+	if x, ok := x.GetSecret().(*Account_Pin); ok {
+		return x.Pin
+	}
+	return ""
+}
+
+func (x *Account) GetHint() string {
+	if x, ok := x.GetSecret().(*Account_Hint); ok {
+		return x.Hint
+	}
+	return ""
+}
+
+type isAccount_Secret interface {
+	isAccount_Secret()
+}
+
+type Account_Pin struct {
+	Pin string `protobuf:"bytes,6,opt,name=pin,proto3,oneof"`
+}
+
+type Account_Hint struct {
+	Hint string `protobuf:"bytes,7,opt,name=hint,proto3,oneof"`
+}
+
+func (*Account_Pin) isAccount_Secret() {}
+
+func (*Account_Hint) isAccount_Secret() {}
+
+var File_tests_testpb_masking_proto protoreflect.FileDescriptor
+
+var file_tests_testpb_masking_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x74, 0x65, 0x73, 0x74, 0x73, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x62, 0x2f, 0x6d,
+	0x61, 0x73, 0x6b, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x62, 0x6c,
+	0x6f, 0x67, 0x67, 0x65, 0x72, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x73, 0x1a, 0x17, 0x62, 0x6c, 0x6f,
+	0x67, 0x67, 0x65, 0x72, 0x70, 0x62, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3a, 0x0a, 0x04, 0x43, 0x61, 0x72, 0x64, 0x12, 0x1c, 0x0a, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x04, 0xe8, 0xe0,
+	0x18, 0x01, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x72,
+	0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x62, 0x72, 0x61, 0x6e, 0x64,
+	0x22, 0xef, 0x02, 0x0a, 0x07, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x08, 0x70,
+	0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x04, 0xe8,
+	0xe0, 0x18, 0x01, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x36, 0x0a,
+	0x0c, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x63, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x2e, 0x74, 0x65,
+	0x73, 0x74, 0x73, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x0b, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72,
+	0x79, 0x43, 0x61, 0x72, 0x64, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x2e, 0x74,
+	0x65, 0x73, 0x74, 0x73, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x05, 0x63, 0x61, 0x72, 0x64, 0x73,
+	0x12, 0x37, 0x0a, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x62, 0x6c, 0x6f, 0x67, 0x67, 0x65, 0x72, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x73, 0x2e,
+	0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x03, 0x70, 0x69, 0x6e,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x42, 0x04, 0xe8, 0xe0, 0x18, 0x01, 0x48, 0x00, 0x52, 0x03,
+	0x70, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x04, 0x68, 0x69, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x04, 0x68, 0x69, 0x6e, 0x74, 0x1a, 0x4d, 0x0a, 0x0a, 0x56, 0x61, 0x75,
+	0x6c, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x29, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x6c, 0x6f, 0x67, 0x67,
+	0x65, 0x72, 0x2e, 0x74, 0x65, 0x73, 0x74, 0x73, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x70, 0x61, 0x77, 0x61, 0x74, 0x74, 0x68, 0x69, 0x72, 0x2f, 0x62, 0x6c, 0x6f, 0x67, 0x67,
+	0x65, 0x72, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x73, 0x2f, 0x74, 0x65, 0x73, 0x74, 0x70, 0x62, 0x3b,
+	0x74, 0x65, 0x73, 0x74, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_tests_testpb_masking_proto_rawDescOnce sync.Once
+	file_tests_testpb_masking_proto_rawDescData = file_tests_testpb_masking_proto_rawDesc
+)
+
+func file_tests_testpb_masking_proto_rawDescGZIP() []byte {
+	file_tests_testpb_masking_proto_rawDescOnce.Do(func() {
+		file_tests_testpb_masking_proto_rawDescData = protoimpl.X.CompressGZIP(file_tests_testpb_masking_proto_rawDescData)
+	})
+	return file_tests_testpb_masking_proto_rawDescData
+}
+
+var file_tests_testpb_masking_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_tests_testpb_masking_proto_goTypes = []any{
+	(*Card)(nil),    // 0: blogger.tests.Card
+	(*Account)(nil), // 1: blogger.tests.Account
+	nil,             // 2: blogger.tests.Account.VaultEntry
+}
+var file_tests_testpb_masking_proto_depIdxs = []int32{
+	0, // 0: blogger.tests.Account.primary_card:type_name -> blogger.tests.Card
+	0, // 1: blogger.tests.Account.cards:type_name -> blogger.tests.Card
+	2, // 2: blogger.tests.Account.vault:type_name -> blogger.tests.Account.VaultEntry
+	0, // 3: blogger.tests.Account.VaultEntry.value:type_name -> blogger.tests.Card
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_tests_testpb_masking_proto_init() }
+func file_tests_testpb_masking_proto_init() {
+	if File_tests_testpb_masking_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_tests_testpb_masking_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Card); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_tests_testpb_masking_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Account); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_tests_testpb_masking_proto_msgTypes[1].OneofWrappers = []any{
+		(*Account_Pin)(nil),
+		(*Account_Hint)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_tests_testpb_masking_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tests_testpb_masking_proto_goTypes,
+		DependencyIndexes: file_tests_testpb_masking_proto_depIdxs,
+		MessageInfos:      file_tests_testpb_masking_proto_msgTypes,
+	}.Build()
+	File_tests_testpb_masking_proto = out.File
+	file_tests_testpb_masking_proto_rawDesc = nil
+	file_tests_testpb_masking_proto_goTypes = nil
+	file_tests_testpb_masking_proto_depIdxs = nil
+}
@@ -0,0 +1,349 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/pawatthir/blogger/middleware/grpcclient"
+	"github.com/pawatthir/blogger/middleware/grpcserver"
+	"github.com/pawatthir/blogger/middleware/httpclient"
+	"github.com/pawatthir/blogger/middleware/httpserver"
+	"github.com/pawatthir/blogger/middleware/nethttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	incomingTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	incomingSpanID  = "00f067aa0ba902b7"
+	traceparent     = "00-" + incomingTraceID + "-" + incomingSpanID + "-01"
+)
+
+// recordingProvider keeps the spans its tracers start, in place of an SDK.
+type recordingProvider struct {
+	embedded.TracerProvider
+
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{provider: p}
+}
+
+func (p *recordingProvider) ended() []*recordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var spans []*recordedSpan
+	for _, span := range p.spans {
+		if span.ended {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+	provider *recordingProvider
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	parent := trace.SpanContextFromContext(ctx)
+
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		_, _ = rand.Read(traceID[:])
+	}
+	var spanID trace.SpanID
+	_, _ = rand.Read(spanID[:])
+
+	span := &recordedSpan{
+		provider: t.provider,
+		name:     name,
+		kind:     config.SpanKind(),
+		parent:   parent,
+		attrs:    map[attribute.Key]attribute.Value{},
+		context: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	span.SetAttributes(config.Attributes()...)
+
+	t.provider.mu.Lock()
+	t.provider.spans = append(t.provider.spans, span)
+	t.provider.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordedSpan struct {
+	noop.Span
+	provider *recordingProvider
+
+	name    string
+	kind    trace.SpanKind
+	parent  trace.SpanContext
+	context trace.SpanContext
+	attrs   map[attribute.Key]attribute.Value
+	status  codes.Code
+	ended   bool
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.context }
+func (s *recordedSpan) IsRecording() bool              { return true }
+
+func (s *recordedSpan) SetName(name string) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.name = name
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.status = code
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.ended = true
+}
+
+func newTracingLogger(t *testing.T, propagators ...string) (*logger.Logger, *recordingProvider, func() string) {
+	provider := &recordingProvider{}
+	instance, buf := newBufferedLogger(t,
+		logger.WithConfig(logger.Config{
+			Env:     "test",
+			Level:   "info",
+			UseJSON: true,
+			Tracing: logger.TracingConfig{Enabled: true, Propagators: propagators},
+		}),
+		logger.WithTracerProvider(provider),
+	)
+	return instance, provider, buf.String
+}
+
+func TestFiberMiddleware_ContinuesW3CTrace(t *testing.T) {
+	instance, provider, output := newTracingLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		logger.FromContext(c.UserContext()).InfoContext(c.UserContext(), "loading order")
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/orders/42", nil)
+	req.Header.Set("traceparent", traceparent)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /orders/:id", span.name)
+	assert.Equal(t, trace.SpanKindServer, span.kind)
+	assert.Equal(t, incomingSpanID, span.parent.SpanID().String())
+	assert.True(t, span.parent.IsRemote())
+	assert.Equal(t, int64(200), span.attrs["http.response.status_code"].AsInt64())
+
+	entry := findMessage(t, output(), "loading order")
+	assert.Equal(t, incomingTraceID, entry["trace_id"])
+	assert.Equal(t, span.context.SpanID().String(), entry["span_id"])
+
+	canonical := decodeLogLines(t, output())
+	assert.Equal(t, incomingTraceID, canonical[len(canonical)-1]["trace_id"], "the canonical log is correlated too")
+}
+
+func TestFiberMiddleware_ServerErrorMarksSpan(t *testing.T) {
+	instance, provider, _ := newTracingLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusServiceUnavailable)
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, err)
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].status)
+	assert.False(t, spans[0].parent.IsValid(), "a request without trace headers starts a new trace")
+}
+
+func TestFiberMiddleware_TracingDisabled(t *testing.T) {
+	instance, buf := newBufferedLogger(t)
+	app := fiber.New()
+	app.Use(httpserver.NewLoggingMiddlewareWithLogger(instance).Logging())
+	app.Get("/", func(c *fiber.Ctx) error {
+		logger.FromContext(c.UserContext()).InfoContext(c.UserContext(), "handling")
+		return c.SendString("ok")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", traceparent)
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.False(t, instance.TracingEnabled())
+	assert.NotContains(t, findMessage(t, buf.String(), "handling"), "trace_id")
+}
+
+func TestNetHTTPMiddleware_ContinuesB3Trace(t *testing.T) {
+	instance, provider, output := newTracingLogger(t, logger.PropagatorB3)
+	handler := nethttp.NewLoggingMiddlewareWithLogger(instance).Logging()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).InfoContext(r.Context(), "handling")
+	}))
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("X-B3-TraceId", "a3ce929d0e0e4736")
+	req.Header.Set("X-B3-SpanId", incomingSpanID)
+	req.Header.Set("X-B3-Sampled", "1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, incomingSpanID, spans[0].parent.SpanID().String())
+	assert.True(t, spans[0].parent.IsSampled())
+	assert.Equal(t, "0000000000000000a3ce929d0e0e4736", findMessage(t, output(), "handling")["trace_id"])
+}
+
+func TestB3Propagator(t *testing.T) {
+	ctx := logger.B3Propagator{}.Extract(context.Background(), propagation.MapCarrier{
+		"b3": incomingTraceID + "-" + incomingSpanID + "-d",
+	})
+	spanCtx := trace.SpanContextFromContext(ctx)
+	require.True(t, spanCtx.IsValid())
+	assert.Equal(t, incomingTraceID, spanCtx.TraceID().String())
+	assert.True(t, spanCtx.IsSampled())
+
+	single := propagation.MapCarrier{}
+	logger.B3Propagator{}.Inject(ctx, single)
+	assert.Equal(t, incomingTraceID+"-"+incomingSpanID+"-1", single.Get("b3"))
+
+	multi := propagation.MapCarrier{}
+	logger.B3Propagator{MultipleHeaders: true}.Inject(ctx, multi)
+	assert.Equal(t, incomingTraceID, multi.Get("x-b3-traceid"))
+	assert.Equal(t, incomingSpanID, multi.Get("x-b3-spanid"))
+	assert.Equal(t, "1", multi.Get("x-b3-sampled"))
+
+	for _, header := range []string{"0", "not-a-trace", incomingTraceID + "-xyz"} {
+		ctx := logger.B3Propagator{}.Extract(context.Background(), propagation.MapCarrier{"b3": header})
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid(), header)
+	}
+}
+
+func TestGRPCInterceptor_ContinuesTrace(t *testing.T) {
+	instance, provider, output := newTracingLogger(t)
+	interceptor := grpcserver.NewUnaryLoggerInterceptorWithLogger(instance).Intercept()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceparent))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			logger.FromContext(ctx).InfoContext(ctx, "in handler")
+			return nil, status.Error(grpccodes.Internal, "boom")
+		})
+	require.Error(t, err)
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "orders.v1.Orders/Get", span.name)
+	assert.Equal(t, trace.SpanKindServer, span.kind)
+	assert.Equal(t, "orders.v1.Orders", span.attrs["rpc.service"].AsString())
+	assert.Equal(t, "Get", span.attrs["rpc.method"].AsString())
+	assert.Equal(t, int64(13), span.attrs["rpc.grpc.status_code"].AsInt64())
+	assert.Equal(t, codes.Error, span.status)
+	assert.Equal(t, incomingTraceID, findMessage(t, output(), "in handler")["trace_id"])
+}
+
+func TestGRPCClientInterceptor_InjectsTrace(t *testing.T) {
+	instance, provider, _ := newTracingLogger(t, logger.PropagatorTraceContext, logger.PropagatorB3Multi)
+	interceptor := grpcclient.UnaryClientLoggingInterceptorWithLogger(instance)
+
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, resp interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	ctx := metadata.AppendToOutgoingContext(traceContext(t, incomingTraceID), "authorization", "Bearer token")
+	require.NoError(t, interceptor(ctx, "/orders.v1.Orders/Get", nil, nil, nil, invoker))
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, trace.SpanKindClient, span.kind)
+	assert.Equal(t, incomingSpanID, span.parent.SpanID().String())
+
+	assert.Equal(t, []string{"00-" + incomingTraceID + "-" + span.context.SpanID().String() + "-01"}, sent.Get("traceparent"))
+	assert.Equal(t, []string{span.context.SpanID().String()}, sent.Get("x-b3-spanid"))
+	assert.Equal(t, []string{"Bearer token"}, sent.Get("authorization"), "existing metadata is kept")
+}
+
+func TestHTTPClient_InjectsTrace(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	instance, provider, _ := newTracingLogger(t, logger.PropagatorB3)
+	config := httpclient.DefaultConfig()
+	config.Logger = instance
+	client := &http.Client{Transport: httpclient.NewLoggingRoundTripper(nil, config)}
+
+	req, err := http.NewRequestWithContext(traceContext(t, incomingTraceID), "GET", server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := provider.ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].status)
+	assert.Equal(t, incomingTraceID+"-"+spans[0].context.SpanID().String()+"-1", received.Get("b3"))
+	assert.Empty(t, req.Header.Get("b3"), "the caller's request is not modified")
+}
+
+func TestTracingConfig_Validation(t *testing.T) {
+	config := logger.Config{Tracing: logger.TracingConfig{Enabled: true, Propagators: []string{"tracecontext", "jaeger"}}}
+	fields := validationFields(t, config.Validate())
+	assert.Contains(t, fields, "tracing.propagators[1]")
+
+	instance, _, _ := newTracingLogger(t)
+	rejected, err := instance.Reconfigure(logger.Config{Env: "test", Level: "info", UseJSON: true})
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "tracing", rejected[0].Field)
+}
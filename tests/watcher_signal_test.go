@@ -0,0 +1,28 @@
+//go:build !windows
+
+package tests
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFile_ReloadsOnSIGHUP(t *testing.T) {
+	path, recorder, _ := startWatchTest(t, time.Hour)
+
+	writeWatchedConfig(t, path, `log:
+  env: production
+  serviceName: watch-service
+  level: error
+  useJsonEncoder: true`)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	require.Eventually(t, func() bool { return logger.GetLevel() == "error" }, 2*time.Second, 10*time.Millisecond)
+	_, _, reloads := recorder.snapshot()
+	assert.Equal(t, 1, reloads)
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pawatthir/blogger/config"
+	"github.com/pawatthir/blogger/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadRecorder struct {
+	mu       sync.Mutex
+	rejected []logger.RejectedChange
+	errs     []error
+	reloads  int
+}
+
+func (r *reloadRecorder) options(interval time.Duration) config.WatchOptions {
+	return config.WatchOptions{
+		Interval: interval,
+		OnReject: func(rejected []logger.RejectedChange, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.rejected = append(r.rejected, rejected...)
+			if err != nil {
+				r.errs = append(r.errs, err)
+			}
+		},
+		OnReload: func(*config.LogConfig) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reloads++
+		},
+	}
+}
+
+func (r *reloadRecorder) snapshot() ([]logger.RejectedChange, []error, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]logger.RejectedChange{}, r.rejected...), append([]error{}, r.errs...), r.reloads
+}
+
+func writeWatchedConfig(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func startWatchTest(t *testing.T, interval time.Duration) (string, *reloadRecorder, *config.Watcher) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeWatchedConfig(t, path, `log:
+  env: production
+  serviceName: watch-service
+  level: info
+  useJsonEncoder: true`)
+
+	logger.Init(logger.Config{Env: "production", ServiceName: "watch-service", Level: "info", UseJSON: true})
+	t.Cleanup(func() { logger.Init(logger.Config{Env: "test", ServiceName: "watch-test", Level: "info", UseJSON: true}) })
+
+	recorder := &reloadRecorder{}
+	watcher, err := config.WatchFile(path, recorder.options(interval))
+	require.NoError(t, err)
+	t.Cleanup(watcher.Stop)
+	return path, recorder, watcher
+}
+
+func TestWatchFile_AppliesSafeChanges(t *testing.T) {
+	path, recorder, _ := startWatchTest(t, 10*time.Millisecond)
+
+	writeWatchedConfig(t, path, `log:
+  env: production
+  serviceName: watch-service
+  level: debug
+  useJsonEncoder: true
+  componentLevels:
+    canonical: error
+  body:
+    maxResponseBytes: 512
+  redaction:
+    fieldRules:
+      - path: card_token
+        strategy: mask`)
+
+	require.Eventually(t, func() bool { return logger.GetLevel() == "debug" }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "error", logger.GetLoggerLevel("canonical"))
+	assert.Equal(t, 512, logger.GetBodyLogConfig().MaxResponseBytes)
+	assert.Equal(t, map[string]interface{}{"card_token": "*****"},
+		logger.GetRedactor().RedactBody("/api/cards", []byte(`{"card_token":"abc"}`)))
+
+	rejected, errs, reloads := recorder.snapshot()
+	assert.Empty(t, rejected)
+	assert.Empty(t, errs)
+	assert.Equal(t, 1, reloads)
+}
+
+func TestWatchFile_ReportsRejectedChanges(t *testing.T) {
+	path, recorder, _ := startWatchTest(t, 10*time.Millisecond)
+
+	writeWatchedConfig(t, path, `log:
+  env: production
+  serviceName: renamed-service
+  level: warn
+  useJsonEncoder: false`)
+
+	require.Eventually(t, func() bool {
+		rejected, _, _ := recorder.snapshot()
+		return len(rejected) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	rejected, _, _ := recorder.snapshot()
+	fields := []string{}
+	for _, change := range rejected {
+		fields = append(fields, change.Field)
+	}
+	assert.ElementsMatch(t, []string{"serviceName", "useJsonEncoder"}, fields)
+	assert.Equal(t, "warn", logger.GetLevel())
+	assert.Equal(t, "watch-service", logger.ServiceName)
+}
+
+func TestWatchFile_InvalidConfigIsNotApplied(t *testing.T) {
+	path, recorder, _ := startWatchTest(t, 10*time.Millisecond)
+
+	writeWatchedConfig(t, path, `log:
+  env: production
+  serviceName: watch-service
+  level: loud
+  useJsonEncoder: true
+  body:
+    maxResponseBytes: 64`)
+
+	require.Eventually(t, func() bool {
+		_, errs, _ := recorder.snapshot()
+		return len(errs) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, errs, reloads := recorder.snapshot()
+	assert.Contains(t, errs[0].Error(), "loud")
+	assert.Equal(t, 0, reloads)
+	assert.Equal(t, "info", logger.GetLevel())
+	assert.Equal(t, 0, logger.GetBodyLogConfig().MaxResponseBytes)
+}
+
+func TestReconfigure_FileRotationSettings(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	cfg := logger.Config{Env: "test", ServiceName: "rotation-test", Level: "info", FileEnabled: true, FilePath: first, FileSize: 10}
+	logger.Init(cfg)
+	defer logger.Init(logger.Config{Env: "test", ServiceName: "watch-test", Level: "info", UseJSON: true})
+
+	logger.Log.Info("before reload")
+
+	cfg.FilePath = second
+	cfg.MaxBackups = 2
+	rejected, err := logger.Reconfigure(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, rejected)
+
+	logger.Log.Info("after reload")
+
+	firstData, err := os.ReadFile(first)
+	require.NoError(t, err)
+	secondData, err := os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Contains(t, string(firstData), "before reload")
+	assert.NotContains(t, string(firstData), "after reload")
+	assert.Contains(t, string(secondData), "after reload")
+}
+
+func TestReconfigure_Sampling(t *testing.T) {
+	file := initWithStdoutFile(t, logger.Config{Env: "test", ServiceName: "sampling-test", Level: "info", UseJSON: true})
+
+	_, err := logger.Reconfigure(logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "info", UseJSON: true,
+		Sampling: logger.SamplingConfig{Enabled: true, Levels: map[string]float64{"info": 0}},
+	})
+	require.NoError(t, err)
+	logger.Log.Info("sampled away")
+
+	_, err = logger.Reconfigure(logger.Config{Env: "test", ServiceName: "sampling-test", Level: "info", UseJSON: true})
+	require.NoError(t, err)
+	logger.Log.Info("kept")
+	_ = logger.Log.Sync()
+
+	assert.Equal(t, 0, countMessages(t, file, "sampled away"))
+	assert.Equal(t, 1, countMessages(t, file, "kept"))
+
+	_, err = logger.Reconfigure(logger.Config{
+		Env: "test", ServiceName: "sampling-test", Level: "info", UseJSON: true,
+		Sampling: logger.SamplingConfig{Enabled: true, Levels: map[string]float64{"info": 2}},
+	})
+	assert.Error(t, err)
+}